@@ -257,6 +257,9 @@ func (coalescer *requestCoalescer) RoundTrip(req *http.Request) (*http.Response,
 
 func collectMetrics(cacheMode CacheResponseMode, req *http.Request, resp *http.Response, tokenBudgetName string) {
 	ghmetrics.CollectCacheRequestMetrics(string(cacheMode), req.URL.Path, req.Header.Get("User-Agent"), tokenBudgetName)
+	if !CacheModeIsFree(cacheMode) {
+		ghmetrics.CollectTokenConsumptionMetrics(req.URL.Path, req.Header.Get("User-Agent"), tokenBudgetName)
+	}
 	if resp != nil {
 		resp.Header.Set(CacheModeHeader, string(cacheMode))
 		if cacheMode == ModeRevalidated && resp.Header.Get(cacheEntryCreationDateHeader) != "" {
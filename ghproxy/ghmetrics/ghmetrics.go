@@ -79,6 +79,16 @@ var cacheCounter = prometheus.NewCounterVec(
 	[]string{"mode", "path", "user_agent", "token_hash"},
 )
 
+// tokensConsumed provides the 'ghcache_tokens_consumed' counter vec that
+// keeps track of the API tokens spent on requests by API path.
+var tokensConsumed = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "ghcache_tokens_consumed",
+		Help: "How many API tokens the requests of each API path consumed.",
+	},
+	[]string{"path", "user_agent", "token_hash"},
+)
+
 // timeoutDuration provides the 'github_request_timeouts' histogram that keeps
 // track of the timeouts of GitHub requests by API path.
 var timeoutDuration = prometheus.NewHistogramVec(
@@ -110,6 +120,7 @@ func init() {
 	prometheus.MustRegister(ghRequestDurationHistVec)
 	prometheus.MustRegister(ghRequestWaitDurationHistVec)
 	prometheus.MustRegister(cacheCounter)
+	prometheus.MustRegister(tokensConsumed)
 	prometheus.MustRegister(timeoutDuration)
 	prometheus.MustRegister(cacheEntryAge)
 }
@@ -179,6 +190,12 @@ func CollectCacheRequestMetrics(mode, path, userAgent, tokenHash string) {
 	cacheCounter.With(prometheus.Labels{"mode": mode, "path": simplifier.Simplify(path), "user_agent": userAgentWithoutVersion(userAgent), "token_hash": tokenHash}).Inc()
 }
 
+// CollectTokenConsumptionMetrics records that a request for a specific path
+// could not be fulfilled for free and consumed an API token
+func CollectTokenConsumptionMetrics(path, userAgent, tokenHash string) {
+	tokensConsumed.With(prometheus.Labels{"path": simplifier.Simplify(path), "user_agent": userAgentWithoutVersion(userAgent), "token_hash": tokenHash}).Inc()
+}
+
 func CollectCacheEntryAgeMetrics(age float64, path, userAgent, tokenHash string) {
 	cacheEntryAge.With(prometheus.Labels{"path": simplifier.Simplify(path), "user_agent": userAgentWithoutVersion(userAgent), "token_hash": tokenHash}).Observe(age)
 }
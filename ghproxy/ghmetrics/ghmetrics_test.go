@@ -0,0 +1,41 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ghmetrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollectTokenConsumptionMetrics(t *testing.T) {
+	tokensConsumed.Reset()
+	CollectTokenConsumptionMetrics("/repos/org/repo/issues/1", "hook/v20220101", "token")
+	CollectTokenConsumptionMetrics("/repos/org/other/issues/2", "hook/v20220102", "token")
+	CollectTokenConsumptionMetrics("/users/someone", "tide", "token")
+
+	expected := `
+# HELP ghcache_tokens_consumed How many API tokens the requests of each API path consumed.
+# TYPE ghcache_tokens_consumed counter
+ghcache_tokens_consumed{path="/repos/:owner/:repo/issues/:issueId",token_hash="token",user_agent="hook"} 2
+ghcache_tokens_consumed{path="/users/:username",token_hash="token",user_agent="tide"} 1
+`
+	if err := testutil.CollectAndCompare(tokensConsumed, strings.NewReader(expected)); err != nil {
+		t.Errorf("unexpected metrics for ghcache_tokens_consumed:\n%s", err)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package summarize
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/tools/cover"
+	"k8s.io/test-infra/gopherage/pkg/cov"
+	"k8s.io/test-infra/gopherage/pkg/cov/junit/calculation"
+	"k8s.io/test-infra/gopherage/pkg/util"
+)
+
+type flags struct {
+	outputFile string
+}
+
+// packageSummary is the JSON representation of the coverage of a package, or of the whole profile.
+type packageSummary struct {
+	Name              string  `json:"name"`
+	CoveredStatements int     `json:"covered_statements"`
+	TotalStatements   int     `json:"total_statements"`
+	Ratio             float32 `json:"ratio"`
+}
+
+type summary struct {
+	Total    packageSummary   `json:"total"`
+	Packages []packageSummary `json:"packages"`
+}
+
+// MakeCommand returns a `summarize` command.
+func MakeCommand() *cobra.Command {
+	flags := &flags{}
+	cmd := &cobra.Command{
+		Use:   "summarize [files...]",
+		Short: "Summarizes Go coverage files per package as JSON.",
+		Long: `Merges the given Go coverage files and produces a JSON summary of the number of
+covered statements in each package and overall. The summary can be compared between a PR and its
+base to gate on coverage changes.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			run(flags, cmd, args)
+		},
+	}
+	cmd.Flags().StringVarP(&flags.outputFile, "output", "o", "-", "output file")
+	return cmd
+}
+
+func toPackageSummary(c calculation.Coverage) packageSummary {
+	return packageSummary{
+		Name:              c.Name,
+		CoveredStatements: c.NumCoveredStmts,
+		TotalStatements:   c.NumAllStmts,
+		Ratio:             c.Ratio(),
+	}
+}
+
+func summarizeProfiles(profiles []*cover.Profile) summary {
+	covList := calculation.ProduceCovList(profiles)
+	// Ratio sums the statements of every file into covList.Coverage
+	covList.Ratio()
+	s := summary{Total: toPackageSummary(*covList.Coverage), Packages: []packageSummary{}}
+	for _, c := range covList.ByDirectory() {
+		s.Packages = append(s.Packages, toPackageSummary(c))
+	}
+	return s
+}
+
+func run(flags *flags, cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Expected at least one file.")
+		cmd.Usage()
+		os.Exit(2)
+	}
+
+	profiles := make([][]*cover.Profile, 0, len(args))
+	for _, path := range args {
+		profile, err := util.LoadProfile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to open %s: %v", path, err)
+			os.Exit(1)
+		}
+		profiles = append(profiles, profile)
+	}
+
+	merged, err := cov.MergeMultipleProfiles(profiles)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to merge files: %v", err)
+		os.Exit(1)
+	}
+
+	var file io.WriteCloser
+	if flags.outputFile == "-" {
+		file = os.Stdout
+	} else {
+		file, err = os.Create(flags.outputFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create file: %v.", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(summarizeProfiles(merged)); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write summary: %v.", err)
+		os.Exit(1)
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"k8s.io/test-infra/gopherage/cmd/junit"
 	"k8s.io/test-infra/gopherage/cmd/merge"
 	"k8s.io/test-infra/gopherage/cmd/metadata"
+	"k8s.io/test-infra/gopherage/cmd/summarize"
 )
 
 var rootCommand = &cobra.Command{
@@ -42,6 +43,7 @@ func run() error {
 	rootCommand.AddCommand(junit.MakeCommand())
 	rootCommand.AddCommand(merge.MakeCommand())
 	rootCommand.AddCommand(metadata.MakeCommand())
+	rootCommand.AddCommand(summarize.MakeCommand())
 	return rootCommand.Execute()
 }
 
@@ -17,6 +17,7 @@ limitations under the License.
 package calculation
 
 import (
+	"reflect"
 	"testing"
 
 	"golang.org/x/tools/cover"
@@ -69,3 +70,23 @@ func TestCovList(t *testing.T) {
 			"expected = %v; actual = %v", expected, covList.Ratio())
 	}
 }
+
+func TestByDirectory(t *testing.T) {
+	covList := &CoverageList{Group: []Coverage{
+		{Name: "a/x.go", NumCoveredStmts: 1, NumAllStmts: 4},
+		{Name: "b/y.go", NumCoveredStmts: 0, NumAllStmts: 2},
+		{Name: "a/z.go", NumCoveredStmts: 3, NumAllStmts: 4},
+		{Name: "a/c/w.go", NumCoveredStmts: 5, NumAllStmts: 5},
+	}}
+
+	expected := []Coverage{
+		{Name: "a", NumCoveredStmts: 4, NumAllStmts: 8},
+		{Name: "a/c", NumCoveredStmts: 5, NumAllStmts: 5},
+		{Name: "b", NumCoveredStmts: 0, NumAllStmts: 2},
+	}
+	actual := covList.ByDirectory()
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Directory level summarized coverage data does not match expectation: "+
+			"expected = %v; actual = %v", expected, actual)
+	}
+}
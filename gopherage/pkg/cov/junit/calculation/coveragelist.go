@@ -18,6 +18,7 @@ package calculation
 
 import (
 	"path"
+	"sort"
 	"strings"
 )
 
@@ -74,3 +75,25 @@ func (covList CoverageList) ListDirectories() []string {
 	}
 	return result
 }
+
+// ByDirectory sums the coverage of the files in each directory (i.e. Go package), sorted by
+// directory name.
+func (covList CoverageList) ByDirectory() []Coverage {
+	dirs := map[string]*Coverage{}
+	for _, cov := range covList.Group {
+		dir := path.Dir(cov.Name)
+		if dirs[dir] == nil {
+			dirs[dir] = &Coverage{Name: dir}
+		}
+		dirs[dir].NumCoveredStmts += cov.NumCoveredStmts
+		dirs[dir].NumAllStmts += cov.NumAllStmts
+	}
+	result := make([]Coverage, 0, len(dirs))
+	for _, cov := range dirs {
+		result = append(result, *cov)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
@@ -18,6 +18,7 @@ package main
 
 import (
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -64,11 +65,15 @@ func monitorDiskAndEvict(
 				// pop entry and delete
 				var entry diskcache.EntryInfo
 				entry, files = files[0], files[1:]
-				err = c.Delete(c.PathToKey(entry.Path))
+				key := c.PathToKey(entry.Path)
+				err = c.Delete(key)
 				if err != nil {
 					logger.WithError(err).Errorf("Error deleting entry at path: %v", entry.Path)
 				} else {
 					promMetrics.FilesEvicted.Inc()
+					if parts := strings.Split(key, "/"); len(parts) > 2 {
+						promMetrics.RepoFilesEvicted.WithLabelValues(cacheRepo(parts[:len(parts)-2])).Inc()
+					}
 					promMetrics.LastEvictedAccessAge.Set(time.Since(entry.LastAccess).Hours())
 				}
 				// get new disk usage
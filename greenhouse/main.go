@@ -131,6 +131,7 @@ func cacheHandler(cache *diskcache.Cache) http.Handler {
 			return
 		}
 		requestingAction := acOrCAS == "ac"
+		repo := cacheRepo(parts[:len(parts)-2])
 
 		// actually handle request depending on method
 		switch m := r.Method; m {
@@ -151,6 +152,7 @@ func cacheHandler(cache *diskcache.Cache) http.Handler {
 					} else {
 						promMetrics.CASMisses.Inc()
 					}
+					promMetrics.Requests.WithLabelValues(repo, acOrCAS, "miss").Inc()
 					http.Error(w, err.Error(), http.StatusNotFound)
 					return
 				}
@@ -165,6 +167,7 @@ func cacheHandler(cache *diskcache.Cache) http.Handler {
 			} else {
 				promMetrics.CASHits.Inc()
 			}
+			promMetrics.Requests.WithLabelValues(repo, acOrCAS, "hit").Inc()
 
 		// handle upload
 		case http.MethodPut:
@@ -189,6 +192,22 @@ func cacheHandler(cache *diskcache.Cache) http.Handler {
 	})
 }
 
+// cacheRepo returns the repo a cache belongs to, given the path segments
+// preceding "ac" or "cas" in a request.
+// caches are keyed as /<org>/<repo>,<toolchain hash> (see
+// images/bootstrap/create_bazel_cache_rcs.sh), the toolchain hash is dropped
+// so that metrics are only labeled by repo
+func cacheRepo(segments []string) string {
+	cache := strings.Trim(strings.Join(segments, "/"), "/")
+	if i := strings.Index(cache, ","); i != -1 {
+		cache = cache[:i]
+	}
+	if cache == "" {
+		return "unknown"
+	}
+	return cache
+}
+
 // helper to update disk metrics
 func updateMetrics(interval time.Duration, diskRoot string) {
 	logger := logrus.WithField("sync-loop", "updateMetrics")
@@ -0,0 +1,59 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCacheRepo(t *testing.T) {
+	testCases := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{
+			name:     "repo with toolchain hash",
+			path:     "/kubernetes/test-infra,0123abcd/cas/deadbeef",
+			expected: "kubernetes/test-infra",
+		},
+		{
+			name:     "repo without toolchain hash",
+			path:     "/kubernetes/kubernetes/ac/deadbeef",
+			expected: "kubernetes/kubernetes",
+		},
+		{
+			name:     "eviction key without leading slash",
+			path:     "kubernetes/kubernetes,0123abcd/ac/deadbeef",
+			expected: "kubernetes/kubernetes",
+		},
+		{
+			name:     "no cache key",
+			path:     "/cas/deadbeef",
+			expected: "unknown",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parts := strings.Split(tc.path, "/")
+			if actual := cacheRepo(parts[:len(parts)-2]); actual != tc.expected {
+				t.Errorf("expected %q but got %q", tc.expected, actual)
+			}
+		})
+	}
+}
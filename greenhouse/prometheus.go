@@ -31,6 +31,8 @@ type prometheusMetrics struct {
 	ActionCacheMisses    prometheus.Counter
 	CASMisses            prometheus.Counter
 	LastEvictedAccessAge prometheus.Gauge
+	Requests             *prometheus.CounterVec
+	RepoFilesEvicted     *prometheus.CounterVec
 }
 
 func initMetrics() *prometheusMetrics {
@@ -71,6 +73,14 @@ func initMetrics() *prometheusMetrics {
 			Name: "bazel_cache_last_evicted_access_age",
 			Help: "Hours since last access of most recently evicted file (at eviction time)",
 		}),
+		Requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "bazel_cache_requests",
+			Help: "number of cache GET requests by repo, cache (ac or cas) and result (hit or miss) since last server start",
+		}, []string{"repo", "cache", "result"}),
+		RepoFilesEvicted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "bazel_cache_repo_evicted_files",
+			Help: "number of files evicted by repo since last server start",
+		}, []string{"repo"}),
 	}
 	prometheus.MustRegister(metrics.DiskFree)
 	prometheus.MustRegister(metrics.DiskUsed)
@@ -81,5 +91,7 @@ func initMetrics() *prometheusMetrics {
 	prometheus.MustRegister(metrics.ActionCacheMisses)
 	prometheus.MustRegister(metrics.CASMisses)
 	prometheus.MustRegister(metrics.LastEvictedAccessAge)
+	prometheus.MustRegister(metrics.Requests)
+	prometheus.MustRegister(metrics.RepoFilesEvicted)
 	return metrics
 }
@@ -1060,10 +1060,10 @@ func (c *aksEngineDeployer) Up() error {
 	return nil
 }
 
-func (c *aksEngineDeployer) Build(b buildStrategy) error {
+func (c *aksEngineDeployer) Build(b buildStrategy, bazelFlags []string) error {
 	if c.aksDeploymentMethod == customHyperkube && !areAllDockerImagesExist(c.customHyperkubeImage) {
 		// Build k8s without any special environment variables
-		if err := b.Build(); err != nil {
+		if err := b.Build(bazelFlags); err != nil {
 			return err
 		}
 		if err := c.buildHyperkube(); err != nil {
@@ -1082,7 +1082,7 @@ func (c *aksEngineDeployer) Build(b buildStrategy) error {
 			return err
 		}
 
-		if err := b.Build(); err != nil {
+		if err := b.Build(bazelFlags); err != nil {
 			return err
 		}
 
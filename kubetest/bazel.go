@@ -0,0 +1,246 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"k8s.io/test-infra/kubetest/util"
+)
+
+// maxBazelFailureLog is how many bytes of a failing target's test.log its
+// testcase in junit_bazel.xml keeps.
+const maxBazelFailureLog = 10 * 1024
+
+// bazelFlags returns the flags kubetest passes to bazel build and bazel test,
+// pointing bazel at remoteCache when set and appending the space separated
+// extra args.
+func bazelFlags(remoteCache, args string) []string {
+	var flags []string
+	if remoteCache != "" {
+		flags = append(flags, "--remote_cache="+remoteCache)
+		if strings.HasPrefix(remoteCache, "https://storage.googleapis.com/") {
+			flags = append(flags, "--google_default_credentials")
+		}
+	}
+	return append(flags, strings.Fields(args)...)
+}
+
+// bazelCommand returns bazel <verb> <flags> -- <targets> in the kubernetes checkout.
+func bazelCommand(verb string, flags []string, targets ...string) *exec.Cmd {
+	args := append([]string{verb}, flags...)
+	args = append(args, "--")
+	cmd := exec.Command("bazel", append(args, targets...)...)
+	cmd.Dir = util.K8s("kubernetes")
+	return cmd
+}
+
+// bazelTest runs bazel test on targets and, when dump is set, collects the
+// results of each target into dump, even when some targets failed.
+func bazelTest(targets, flags []string, dump string) error {
+	cmd := bazelCommand("test", flags, targets...)
+	err := control.FinishRunning(cmd)
+	if dump == "" {
+		return err
+	}
+	if cerr := collectBazelTestLogs(filepath.Join(cmd.Dir, "bazel-testlogs"), dump, targets); cerr != nil {
+		if err != nil {
+			log.Printf("Failed to collect bazel test results: %v", cerr)
+			return err
+		}
+		return fmt.Errorf("failed to collect bazel test results: %w", cerr)
+	}
+	return err
+}
+
+// bazelTestXML is the part of a bazel test.xml kubetest reads.
+type bazelTestXML struct {
+	Suites []struct {
+		Cases []struct {
+			Failure *struct{} `xml:"failure"`
+			Error   *struct{} `xml:"error"`
+		} `xml:"testcase"`
+	} `xml:"testsuite"`
+}
+
+// collectBazelTestLogs copies the test.xml and test.log of each of the
+// targets below root to dump/bazel-testlogs and writes a dump/junit_bazel.xml
+// with one testcase per target, like hack/coalesce.py. The results of other
+// targets, left behind by earlier bazel invocations, are skipped. The shards
+// and runs of a target are reported as a single testcase.
+func collectBazelTestLogs(root, dump string, targets []string) error {
+	// bazel-testlogs is a symlink into the bazel output base.
+	root, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return err
+	}
+	suite := util.TestSuite{Name: "bazel"}
+	cases := map[string]int{} // index into suite.Cases by target
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || info.Name() != "test.xml" {
+			return nil
+		}
+		pkg := filepath.Dir(path)
+		rel, err := filepath.Rel(root, pkg)
+		if err != nil {
+			return err
+		}
+		target := bazelTarget(rel)
+		if !bazelTargetRequested(target, targets) {
+			return nil
+		}
+		dest := filepath.Join(dump, "bazel-testlogs", rel)
+		if err := os.MkdirAll(dest, 0755); err != nil {
+			return err
+		}
+		result, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dest, "test.xml"), result, 0644); err != nil {
+			return err
+		}
+		testLog, err := ioutil.ReadFile(filepath.Join(pkg, "test.log"))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err == nil {
+			if err := ioutil.WriteFile(filepath.Join(dest, "test.log"), testLog, 0644); err != nil {
+				return err
+			}
+		}
+
+		i, ok := cases[target]
+		if !ok {
+			i = len(suite.Cases)
+			cases[target] = i
+			suite.Cases = append(suite.Cases, util.TestCase{ClassName: "bazel", Name: target})
+			suite.Tests++
+		}
+		tc := &suite.Cases[i]
+		var parsed bazelTestXML
+		if err := xml.Unmarshal(result, &parsed); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if bazelTestFailed(parsed) {
+			failure := string(testLog)
+			if failure == "" {
+				failure = "failed"
+			}
+			if tc.Failure == "" {
+				suite.Failures++
+			} else {
+				// Another shard or run of the target failed, too.
+				failure = tc.Failure + "\n" + failure
+			}
+			if len(failure) > maxBazelFailureLog {
+				failure = failure[len(failure)-maxBazelFailureLog:]
+			}
+			tc.Failure = failure
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	out, err := xml.MarshalIndent(&suite, "", "    ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dump, "junit_bazel.xml"), append([]byte(xml.Header), out...), 0644)
+}
+
+func bazelTestFailed(parsed bazelTestXML) bool {
+	for _, s := range parsed.Suites {
+		for _, c := range s.Cases {
+			if c.Failure != nil || c.Error != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bazelShardDir matches the bazel-testlogs directories bazel puts the results
+// of a shard or run of a target into, like shard_1_of_3 or shard_1_of_3_run_2_of_2.
+var bazelShardDir = regexp.MustCompile(`^(shard_\d+_of_\d+(_run_\d+_of_\d+)?|run_\d+_of_\d+)$`)
+
+// bazelTarget turns the bazel-testlogs directory of a target, like
+// test/e2e/e2e_test or test/e2e/e2e_test/shard_1_of_3, into its label, like
+// //test/e2e:e2e_test.
+func bazelTarget(rel string) string {
+	rel = filepath.ToSlash(rel)
+	if dir, base := path.Split(rel); dir != "" && bazelShardDir.MatchString(base) {
+		rel = strings.TrimSuffix(dir, "/")
+	}
+	i := strings.LastIndex(rel, "/")
+	if i < 0 {
+		return "//:" + rel
+	}
+	return "//" + rel[:i] + ":" + rel[i+1:]
+}
+
+// bazelTargetRequested returns whether the label of a test target matches the
+// target patterns bazel test was run with, like //pkg:foo_test, //pkg/...,
+// //pkg:all or -//pkg/sub/....
+func bazelTargetRequested(label string, patterns []string) bool {
+	requested := false
+	for _, p := range patterns {
+		if strings.HasPrefix(p, "-") {
+			if bazelPatternMatches(label, p[1:]) {
+				requested = false
+			}
+		} else if bazelPatternMatches(label, p) {
+			requested = true
+		}
+	}
+	return requested
+}
+
+// bazelPatternMatches returns whether the label matches a single target pattern.
+func bazelPatternMatches(label, pattern string) bool {
+	i := strings.Index(label, ":")
+	pkg, name := label[len("//"):i], label[i+1:]
+	pattern = strings.TrimPrefix(pattern, "//")
+	patternPkg, patternName := pattern, ""
+	if i := strings.Index(pattern, ":"); i >= 0 {
+		patternPkg, patternName = pattern[:i], pattern[i+1:]
+	}
+	all := patternName == "all" || patternName == "*" || patternName == "all-targets"
+	if patternPkg == "..." || strings.HasSuffix(patternPkg, "/...") {
+		prefix := strings.TrimSuffix(patternPkg, "...")
+		return (patternName == "" || all) && strings.HasPrefix(pkg+"/", prefix)
+	}
+	if patternName == "" {
+		// //pkg/foo is short for //pkg/foo:foo
+		patternName = path.Base(patternPkg)
+	}
+	return pkg == patternPkg && (all || name == patternName)
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/test-infra/kubetest/util"
+)
+
+func TestBazelFlags(t *testing.T) {
+	cases := []struct {
+		cache string
+		args  string
+		want  []string
+	}{
+		{},
+		{
+			cache: "https://storage.googleapis.com/bucket/cache",
+			args:  "--config=ci  --jobs=8",
+			want:  []string{"--remote_cache=https://storage.googleapis.com/bucket/cache", "--google_default_credentials", "--config=ci", "--jobs=8"},
+		},
+		{
+			cache: "grpc://cache:9092",
+			want:  []string{"--remote_cache=grpc://cache:9092"},
+		},
+	}
+	for _, tc := range cases {
+		if got := bazelFlags(tc.cache, tc.args); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("bazelFlags(%q, %q) = %q, want %q", tc.cache, tc.args, got, tc.want)
+		}
+	}
+}
+
+func TestBazelTarget(t *testing.T) {
+	for rel, want := range map[string]string{
+		"test/e2e/e2e_test":                    "//test/e2e:e2e_test",
+		"foo_test":                             "//:foo_test",
+		"pkg/foo_test/shard_2_of_3":            "//pkg:foo_test",
+		"pkg/foo_test/run_1_of_2":              "//pkg:foo_test",
+		"pkg/foo_test/shard_1_of_3_run_2_of_2": "//pkg:foo_test",
+		"pkg/shard_test":                       "//pkg:shard_test",
+	} {
+		if got := bazelTarget(rel); got != want {
+			t.Errorf("bazelTarget(%q) = %q, want %q", rel, got, want)
+		}
+	}
+}
+
+func TestCollectBazelTestLogs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bazel-testlogs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	out := filepath.Join(dir, "output-base")
+	write := func(rel, content string) {
+		p := filepath.Join(out, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("pkg/pass_test/test.xml", `<testsuites><testsuite><testcase name="TestOK"/></testsuite></testsuites>`)
+	write("pkg/pass_test/test.log", "PASS")
+	write("pkg/sub/fail_test/test.xml", `<testsuites><testsuite><testcase name="TestBad"><failure message="boom"/></testcase></testsuite></testsuites>`)
+	write("pkg/sub/fail_test/test.log", "--- FAIL: TestBad")
+	write("pkg/sharded_test/shard_1_of_2/test.xml", `<testsuites><testsuite><testcase name="TestOK"/></testsuite></testsuites>`)
+	write("pkg/sharded_test/shard_2_of_2/test.xml", `<testsuites><testsuite><testcase name="TestFlaky"><error message="boom"/></testcase></testsuite></testsuites>`)
+	write("pkg/sharded_test/shard_2_of_2/test.log", "--- FAIL: TestFlaky")
+	// Left behind by an earlier bazel test of other targets.
+	write("stale/old_test/test.xml", `<testsuites><testsuite><testcase name="TestOld"><failure message="old"/></testcase></testsuite></testsuites>`)
+	root := filepath.Join(dir, "bazel-testlogs")
+	if err := os.Symlink(out, root); err != nil {
+		t.Fatal(err)
+	}
+
+	dump := filepath.Join(dir, "artifacts")
+	if err := collectBazelTestLogs(root, dump, []string{"//pkg/...", "-//pkg/excluded/..."}); err != nil {
+		t.Fatalf("collectBazelTestLogs() failed: %v", err)
+	}
+
+	for _, rel := range []string{"pkg/pass_test/test.xml", "pkg/sub/fail_test/test.log"} {
+		if _, err := os.Stat(filepath.Join(dump, "bazel-testlogs", rel)); err != nil {
+			t.Errorf("expected %s to be collected: %v", rel, err)
+		}
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dump, "junit_bazel.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var suite struct {
+		Tests    int             `xml:"tests,attr"`
+		Failures int             `xml:"failures,attr"`
+		Cases    []util.TestCase `xml:"testcase"`
+	}
+	if err := xml.Unmarshal(b, &suite); err != nil {
+		t.Fatal(err)
+	}
+	if suite.Tests != 3 || suite.Failures != 2 || len(suite.Cases) != 3 {
+		t.Fatalf("expected 3 tests with 2 failures, got %d tests (%d cases) with %d failures", suite.Tests, len(suite.Cases), suite.Failures)
+	}
+	pass, sharded, fail := suite.Cases[0], suite.Cases[1], suite.Cases[2]
+	if pass.Name != "//pkg:pass_test" || pass.Failure != "" {
+		t.Errorf("unexpected passing case: %+v", pass)
+	}
+	if fail.Name != "//pkg/sub:fail_test" || !strings.Contains(fail.Failure, "FAIL: TestBad") {
+		t.Errorf("unexpected failing case: %+v", fail)
+	}
+	if sharded.Name != "//pkg:sharded_test" || !strings.Contains(sharded.Failure, "FAIL: TestFlaky") {
+		t.Errorf("unexpected sharded case: %+v", sharded)
+	}
+	if _, err := os.Stat(filepath.Join(dump, "bazel-testlogs", "stale")); !os.IsNotExist(err) {
+		t.Errorf("expected the results of unrequested targets to be skipped: %v", err)
+	}
+}
+
+func TestBazelTargetRequested(t *testing.T) {
+	cases := []struct {
+		label    string
+		patterns []string
+		want     bool
+	}{
+		{label: "//pkg:foo_test", patterns: []string{"//pkg:foo_test"}, want: true},
+		{label: "//pkg:foo_test", patterns: []string{"//pkg:bar_test"}},
+		{label: "//pkg/foo:foo", patterns: []string{"//pkg/foo"}, want: true},
+		{label: "//pkg:foo_test", patterns: []string{"//pkg:all"}, want: true},
+		{label: "//pkg/sub:foo_test", patterns: []string{"//pkg:all"}},
+		{label: "//pkg/sub:foo_test", patterns: []string{"//pkg/..."}, want: true},
+		{label: "//pkg:foo_test", patterns: []string{"//pkg/..."}, want: true},
+		{label: "//pkgs:foo_test", patterns: []string{"//pkg/..."}},
+		{label: "//:foo_test", patterns: []string{"//..."}, want: true},
+		{label: "//pkg/sub:foo_test", patterns: []string{"//pkg/...", "-//pkg/sub/..."}},
+		{label: "//pkg:foo_test", patterns: []string{"//pkg/...", "-//pkg/sub/..."}, want: true},
+	}
+	for _, tc := range cases {
+		if got := bazelTargetRequested(tc.label, tc.patterns); got != tc.want {
+			t.Errorf("bazelTargetRequested(%q, %q) = %t, want %t", tc.label, tc.patterns, got, tc.want)
+		}
+	}
+}
@@ -68,7 +68,8 @@ func (b *buildStrategy) Enabled() bool {
 
 // Build kubernetes according to specified strategy.
 // This may be a bazel, host-go, quick or full release build depending on --build=B.
-func (b *buildStrategy) Build() error {
+// Bazel builds pass bazelFlags to bazel, see bazelFlags().
+func (b *buildStrategy) Build(bazelFlags []string) error {
 	var target string
 	switch *b {
 	case "bazel":
@@ -98,17 +99,19 @@ func (b *buildStrategy) Build() error {
 
 	if *b == "gce-windows-bazel" {
 		// Build Linux aritifacts
-		cmd := exec.Command("bazel", "build", "--config=cross:linux_amd64", "//build/release-tars")
-		cmd.Dir = util.K8s("kubernetes")
+		cmd := bazelCommand("build", append([]string{"--config=cross:linux_amd64"}, bazelFlags...), "//build/release-tars")
 		err := control.FinishRunning(cmd)
 		if err != nil {
 			return err
 		}
 		// Build windows aritifacts
-		cmd = exec.Command("bazel", "build", "--config=cross:windows_amd64", "//build/release-tars")
-		cmd.Dir = util.K8s("kubernetes")
+		cmd = bazelCommand("build", append([]string{"--config=cross:windows_amd64"}, bazelFlags...), "//build/release-tars")
 		return control.FinishRunning(cmd)
 	}
+	if target == "bazel-release" && len(bazelFlags) > 0 {
+		// make bazel-release cannot pass flags through to bazel.
+		return control.FinishRunning(bazelCommand("build", bazelFlags, "//build/release-tars"))
+	}
 
 	// TODO(fejta): FIX ME
 	// The build-release script needs stdin to ask the user whether
@@ -0,0 +1,112 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+)
+
+// Phases recorded in the checkpoint file.
+const (
+	phaseUp   = "up"
+	phaseTest = "test"
+	phaseDump = "dump"
+)
+
+// checkpoint records which phases of a kubetest run completed successfully,
+// so that a later run with --resume can skip them and reuse the cluster.
+//
+// A nil checkpoint is valid and records nothing.
+type checkpoint struct {
+	path string
+
+	Completed []string `json:"completed"`
+}
+
+// newCheckpoint returns the checkpoint stored at path. Unless resume is set,
+// any previous state is discarded and the run starts from scratch. An empty
+// path disables checkpointing.
+func newCheckpoint(path string, resume bool) (*checkpoint, error) {
+	if path == "" {
+		if resume {
+			return nil, fmt.Errorf("--resume requires --checkpoint-file")
+		}
+		return nil, nil
+	}
+	c := &checkpoint{path: path}
+	if !resume {
+		return c, c.write()
+	}
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		log.Printf("No checkpoint found at %s, starting from scratch", path)
+		return c, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint %s: %w", path, err)
+	}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	log.Printf("Resuming from checkpoint %s, completed phases: %v", path, c.Completed)
+	return c, nil
+}
+
+// done returns whether the phase completed in a previous run.
+func (c *checkpoint) done(phase string) bool {
+	if c == nil {
+		return false
+	}
+	for _, p := range c.Completed {
+		if p == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// record marks the phase as completed and persists the checkpoint.
+func (c *checkpoint) record(phase string) error {
+	if c == nil || c.done(phase) {
+		return nil
+	}
+	c.Completed = append(c.Completed, phase)
+	return c.write()
+}
+
+// reset forgets all completed phases, e.g. because the cluster is gone.
+func (c *checkpoint) reset() error {
+	if c == nil {
+		return nil
+	}
+	c.Completed = nil
+	return c.write()
+}
+
+func (c *checkpoint) write() error {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(c.path, b, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint %s: %w", c.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state.json")
+
+	c, err := newCheckpoint(path, true)
+	if err != nil {
+		t.Fatalf("Resuming without a checkpoint should start from scratch: %v", err)
+	}
+	if c.done(phaseUp) {
+		t.Error("Expected no phase to be completed")
+	}
+	if err := c.record(phaseUp); err != nil {
+		t.Fatalf("Failed to record phase: %v", err)
+	}
+
+	resumed, err := newCheckpoint(path, true)
+	if err != nil {
+		t.Fatalf("Failed to resume: %v", err)
+	}
+	if !resumed.done(phaseUp) || resumed.done(phaseTest) {
+		t.Errorf("Expected only %q to be completed, got %v", phaseUp, resumed.Completed)
+	}
+
+	fresh, err := newCheckpoint(path, false)
+	if err != nil {
+		t.Fatalf("Failed to start fresh: %v", err)
+	}
+	if fresh.done(phaseUp) {
+		t.Error("Expected a run without --resume to discard previous state")
+	}
+
+	if err := resumed.reset(); err != nil {
+		t.Fatalf("Failed to reset: %v", err)
+	}
+	if again, err := newCheckpoint(path, true); err != nil || again.done(phaseUp) {
+		t.Errorf("Expected reset to forget completed phases, got %v (err: %v)", again, err)
+	}
+}
+
+func TestNilCheckpoint(t *testing.T) {
+	c, err := newCheckpoint("", false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := c.record(phaseUp); err != nil {
+		t.Errorf("Recording on a disabled checkpoint should be a no-op: %v", err)
+	}
+	if c.done(phaseUp) {
+		t.Error("Disabled checkpoint should never report a phase as completed")
+	}
+	if _, err := newCheckpoint("", true); err == nil {
+		t.Error("Expected --resume without --checkpoint-file to fail")
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"k8s.io/test-infra/kubetest/e2e"
+	"k8s.io/test-infra/kubetest/process"
+	"k8s.io/test-infra/kubetest/util"
+)
+
+// conformanceTester runs the conformance suite with another tester and lays
+// out the results the way a conformance submission expects them: the test
+// output in e2e.log next to the junit_01.xml report.
+type conformanceTester struct {
+	tester e2e.Tester
+	dir    string
+}
+
+var _ e2e.Tester = &conformanceTester{}
+
+func (t *conformanceTester) Run(control *process.Control, args []string) error {
+	if err := os.MkdirAll(t.dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(filepath.Join(t.dir, "e2e.log"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	args = t.prepare(args)
+	return control.CaptureOutput(f, func() error {
+		return t.tester.Run(control, args)
+	})
+}
+
+// prepare locks the wrapped tester down to the conformance suite and returns
+// the args to run it with.
+func (t *conformanceTester) prepare(args []string) []string {
+	if g, ok := t.tester.(*e2e.GinkgoTester); ok {
+		// The GinkgoTester passes these flags itself, its suite was
+		// already locked down when it was built.
+		g.Provider = "skeleton"
+		g.ReportDir = t.dir
+		return withoutConformanceFlags(args)
+	}
+	return conformanceArgs(args, t.dir)
+}
+
+// conformanceFlags are the e2e.test flags a conformance run locks down.
+var conformanceFlags = []string{"--ginkgo.focus", "--ginkgo.skip", "--ginkgo.flakeAttempts", "--provider", "--report-dir"}
+
+// withoutConformanceFlags drops the conformanceFlags from args.
+func withoutConformanceFlags(args []string) []string {
+	f := append([]string{}, args...)
+	for _, flag := range conformanceFlags {
+		f, _, _ = util.ExtractField(f, flag)
+	}
+	return f
+}
+
+// conformanceArgs locks the e2e.test args to the conformance suite: only
+// conformance tests without skips or flake retries, against the skeleton
+// provider so no vendor specific behavior is tested, reporting to dir.
+func conformanceArgs(args []string, dir string) []string {
+	return append(withoutConformanceFlags(args),
+		"--ginkgo.focus="+e2e.ConformanceFocus,
+		"--ginkgo.flakeAttempts=1",
+		"--provider=skeleton",
+		"--report-dir="+dir,
+	)
+}
+
+// conformanceDir returns the conformance directory below the --report-dir of args, or else below dump.
+func conformanceDir(args []string, dump string) string {
+	if _, dir, ok := util.ExtractField(args, "--report-dir"); ok {
+		dump = dir
+	}
+	return filepath.Join(dump, "conformance")
+}
@@ -45,27 +45,38 @@ type Tester struct {
 
 // BuildTester returns an object that knows how to test the cluster it deployed.
 func (d *Deployer) BuildTester(o *e2e.BuildTesterOptions) (e2e.Tester, error) {
-	reportdir, err := os.Getwd()
+	// The e2e binaries are looked up in, and the results written to, the
+	// working directory.
+	cwd, err := os.Getwd()
 	if err != nil {
 		return nil, err
 	}
 
-	if o.FocusRegex == "" {
-		o.FocusRegex = "\".*\""
-	}
-	if o.SkipRegex == "" {
-		o.SkipRegex = "\".*(Feature)|(NFS)|(StatefulSet).*\""
+	if !o.Conformance {
+		if o.FocusRegex == "" {
+			o.FocusRegex = "\".*\""
+		}
+		if o.SkipRegex == "" {
+			o.SkipRegex = "\".*(Feature)|(NFS)|(StatefulSet).*\""
+		}
 	}
 
 	t := e2e.NewGinkgoTester(o)
 
 	t.Seed = 1436380640
-	t.GinkgoParallel = 10
 	t.Kubeconfig = d.kubecfg
-	t.FlakeAttempts = 2
+	// The cluster may come from any vendor, so disable provider specific behavior.
+	t.Provider = "skeleton"
+	t.KubeRoot = cwd
+	if !o.Conformance {
+		t.GinkgoParallel = 10
+		if o.FlakeAttempts == 0 {
+			t.FlakeAttempts = 2
+		}
+	}
 	t.NumNodes = 4
 	t.SystemdServices = []string{"docker", "kubelet"}
-	t.ReportDir = reportdir
+	t.ReportDir = cwd
 
 	return t, nil
 }
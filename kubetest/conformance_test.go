@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/test-infra/kubetest/e2e"
+)
+
+func TestConformanceArgs(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "defaults",
+			want: []string{
+				"--ginkgo.focus=\\[Conformance\\]",
+				"--ginkgo.flakeAttempts=1",
+				"--provider=skeleton",
+				"--report-dir=/dump/conformance",
+			},
+		},
+		{
+			name: "overrides the suite, provider and report dir",
+			args: []string{
+				"--ginkgo.focus=Feature:Foo",
+				"--ginkgo.skip=Serial",
+				"--provider=gce",
+				"--report-dir=/dump",
+				"--minStartupPods=8",
+			},
+			want: []string{
+				"--minStartupPods=8",
+				"--ginkgo.focus=\\[Conformance\\]",
+				"--ginkgo.flakeAttempts=1",
+				"--provider=skeleton",
+				"--report-dir=/dump/conformance",
+			},
+		},
+	}
+	for _, tc := range cases {
+		if got := conformanceArgs(tc.args, "/dump/conformance"); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: conformanceArgs() = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestConformanceDir(t *testing.T) {
+	if got, want := conformanceDir([]string{"--report-dir=/dump/soak-1"}, "/dump"), "/dump/soak-1/conformance"; got != want {
+		t.Errorf("conformanceDir() = %q, want %q", got, want)
+	}
+	if got, want := conformanceDir(nil, "/dump"), "/dump/conformance"; got != want {
+		t.Errorf("conformanceDir() = %q, want %q", got, want)
+	}
+}
+
+func TestConformanceTesterConfiguresGinkgoTester(t *testing.T) {
+	g := e2e.NewGinkgoTester(&e2e.BuildTesterOptions{Conformance: true})
+	g.Provider = "gce"
+	tester := &conformanceTester{tester: g, dir: "/dump/conformance"}
+	args := tester.prepare([]string{"--ginkgo.focus=Feature:Foo", "--report-dir=/dump", "--minStartupPods=8"})
+
+	if want := []string{"--minStartupPods=8"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("expected the GinkgoTester to set the conformance flags itself, got args %q, want %q", args, want)
+	}
+	if g.Provider != "skeleton" || g.ReportDir != "/dump/conformance" {
+		t.Errorf("expected provider skeleton and report dir /dump/conformance, got %s and %s", g.Provider, g.ReportDir)
+	}
+	if g.FocusRegex != e2e.ConformanceFocus || g.FlakeAttempts != 1 {
+		t.Errorf("expected the conformance suite without flake attempts, got focus %q and %d flake attempts", g.FocusRegex, g.FlakeAttempts)
+	}
+}
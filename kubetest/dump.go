@@ -27,6 +27,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"golang.org/x/crypto/ssh"
 )
@@ -42,25 +43,42 @@ type logDumper struct {
 
 	// DumpSysctls will record sysctl values from each node
 	DumpSysctls bool
+
+	// Parallelism is how many nodes are dumped at the same time
+	Parallelism int
+
+	// SerialConsole will, if set, record the cloud provider's serial console
+	// output of each instance, which works even if the node is unreachable
+	SerialConsole func(ctx context.Context, instance string, w io.Writer) error
 }
 
+// defaultDumpParallelism is how many nodes a logDumper dumps at the same time by default
+const defaultDumpParallelism = 10
+
 // newLogDumper is the constructor for a logDumper
 func newLogDumper(sshClientFactory sshClientFactory, artifactsDir string) (*logDumper, error) {
 	d := &logDumper{
 		sshClientFactory: sshClientFactory,
 		artifactsDir:     artifactsDir,
+		Parallelism:      defaultDumpParallelism,
 	}
 
 	d.services = []string{
 		"node-problem-detector",
 		"kubelet",
 		"containerd",
+		"crio",
 		"docker",
 		"kops-configuration",
 		"protokube",
+		"kube-node-installation",
+		"kube-node-configuration",
+		"kube-container-runtime-monitor",
+		"kubelet-monitor",
 	}
 	d.files = []string{
 		"kube-apiserver",
+		"kube-apiserver-audit",
 		"kube-scheduler",
 		"rescheduler",
 		"kube-controller-manager",
@@ -77,6 +95,7 @@ func newLogDumper(sshClientFactory sshClientFactory, artifactsDir string) (*logD
 		"startupscript",
 		"kern",
 		"docker",
+		"containerd",
 	}
 
 	return d, nil
@@ -94,12 +113,8 @@ func (d *logDumper) DumpAllNodes(ctx context.Context, additionalIPs []string) er
 	if err != nil {
 		log.Printf("Failed to get nodes for dumping via kubectl: %v", err)
 	} else {
+		var targets []dumpTarget
 		for i := range nodes.Items {
-			if ctx.Err() != nil {
-				log.Printf("stopping dumping nodes: %v", ctx.Err())
-				return ctx.Err()
-			}
-
 			node := &nodes.Items[i]
 
 			ip := ""
@@ -110,32 +125,76 @@ func (d *logDumper) DumpAllNodes(ctx context.Context, additionalIPs []string) er
 				}
 			}
 
-			err := d.dumpNode(ctx, node.Metadata.Name, ip)
-			if err != nil {
-				log.Printf("could not dump node %s (%s): %v", node.Metadata.Name, ip, err)
-			} else {
-				dumped = append(dumped, node)
-			}
+			targets = append(targets, dumpTarget{name: node.Metadata.Name, ip: ip, node: node})
 		}
-	}
-
-	notDumped := findInstancesNotDumped(additionalIPs, dumped)
-	for _, ip := range notDumped {
+		dumped = d.dumpNodes(ctx, targets)
 		if ctx.Err() != nil {
 			log.Printf("stopping dumping nodes: %v", ctx.Err())
 			return ctx.Err()
 		}
+	}
 
+	var targets []dumpTarget
+	for _, ip := range findInstancesNotDumped(additionalIPs, dumped) {
 		log.Printf("dumping node not registered in kubernetes: %s", ip)
-		err := d.dumpNode(ctx, ip, ip)
-		if err != nil {
-			log.Printf("error dumping node %s: %v", ip, err)
-		}
+		targets = append(targets, dumpTarget{name: ip, ip: ip})
+	}
+	d.dumpNodes(ctx, targets)
+	if ctx.Err() != nil {
+		log.Printf("stopping dumping nodes: %v", ctx.Err())
+		return ctx.Err()
 	}
 
 	return nil
 }
 
+// dumpTarget is a node to dump, node is nil if it is not registered in kubernetes
+type dumpTarget struct {
+	name string
+	ip   string
+	node *node
+}
+
+// dumpNodes dumps up to Parallelism targets at the same time and returns the
+// registered nodes it dumped
+func (d *logDumper) dumpNodes(ctx context.Context, targets []dumpTarget) []*node {
+	parallelism := d.Parallelism
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+
+	var (
+		lock   sync.Mutex
+		wg     sync.WaitGroup
+		dumped []*node
+	)
+	sem := make(chan struct{}, parallelism)
+	for _, t := range targets {
+		if ctx.Err() != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(t dumpTarget) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := d.dumpNode(ctx, t.name, t.ip); err != nil {
+				log.Printf("could not dump node %s (%s): %v", t.name, t.ip, err)
+				return
+			}
+			if t.node != nil {
+				lock.Lock()
+				dumped = append(dumped, t.node)
+				lock.Unlock()
+			}
+		}(t)
+	}
+	wg.Wait()
+	return dumped
+}
+
 // findInstancesNotDumped returns ips from the slice that do not appear as any address of the nodes
 func findInstancesNotDumped(ips []string, dumped []*node) []string {
 	var notDumped []string
@@ -156,6 +215,12 @@ func findInstancesNotDumped(ips []string, dumped []*node) []string {
 
 // DumpNode connects to a node and dumps the logs.
 func (d *logDumper) dumpNode(ctx context.Context, name string, ip string) error {
+	if d.SerialConsole != nil {
+		if err := d.dumpSerialConsole(ctx, name); err != nil {
+			log.Printf("error dumping serial console of %s: %v", name, err)
+		}
+	}
+
 	if ip == "" {
 		return fmt.Errorf("could not find address for %v, ", name)
 	}
@@ -183,6 +248,22 @@ func (d *logDumper) dumpNode(ctx context.Context, name string, ip string) error
 	return nil
 }
 
+// dumpSerialConsole records the serial console output of the instance
+func (d *logDumper) dumpSerialConsole(ctx context.Context, name string) error {
+	destPath := filepath.Join(d.artifactsDir, name, "serial-console.log")
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("unable to mkdir on %q: %w", filepath.Dir(destPath), err)
+	}
+
+	f, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("error creating file %q: %w", destPath, err)
+	}
+	defer f.Close()
+
+	return d.SerialConsole(ctx, name, f)
+}
+
 func (d *logDumper) dumpPods(ctx context.Context, namespace string, labelSelector []string) error {
 	pods, err := kubectlGetPods(ctx, namespace, labelSelector)
 	if err != nil {
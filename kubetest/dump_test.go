@@ -318,6 +318,66 @@ func Test_logDumperNode_dump(t *testing.T) {
 	}
 }
 
+func Test_logDumper_dumpNodes(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Errorf("error creating temp dir: %v", err)
+		return
+	}
+
+	defer func() {
+		if err := os.RemoveAll(tmpdir); err != nil {
+			t.Errorf("error removing temp dir: %v", err)
+		}
+	}()
+
+	mockSSHClientFactory := &mockSSHClientFactory{
+		clients: map[string]sshClient{
+			"host1": &mockSSHClient{},
+			"host2": &mockSSHClient{},
+			"host3": &mockSSHClient{},
+		},
+	}
+
+	dumper, err := newLogDumper(mockSSHClientFactory, tmpdir)
+	if err != nil {
+		t.Errorf("error building logDumper: %v", err)
+	}
+	dumper.Parallelism = 2
+	dumper.SerialConsole = func(ctx context.Context, instance string, w io.Writer) error {
+		_, err := fmt.Fprintf(w, "console of %s", instance)
+		return err
+	}
+
+	var targets []dumpTarget
+	for _, name := range []string{"node1", "node2", "node3", "node4"} {
+		n := &node{Metadata: metadata{Name: name}}
+		// node4 is unreachable
+		ip := strings.Replace(name, "node", "host", 1)
+		targets = append(targets, dumpTarget{name: name, ip: ip, node: n})
+	}
+
+	var actual []string
+	for _, n := range dumper.dumpNodes(context.Background(), targets) {
+		actual = append(actual, n.Metadata.Name)
+	}
+	sort.Strings(actual)
+	if expected := []string{"node1", "node2", "node3"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected dumped nodes: actual=%v, expected=%v", actual, expected)
+	}
+
+	for _, target := range targets {
+		b, err := ioutil.ReadFile(filepath.Join(tmpdir, target.name, "serial-console.log"))
+		if err != nil {
+			t.Errorf("error reading serial console of %s: %v", target.name, err)
+			continue
+		}
+		if expected := "console of " + target.name; string(b) != expected {
+			t.Errorf("unexpected serial console of %s: actual=%q, expected=%q", target.name, string(b), expected)
+		}
+	}
+}
+
 // mockCommand is an expected command and canned response
 type mockCommand struct {
 	command string
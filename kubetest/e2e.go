@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -25,6 +26,7 @@ import (
 	"path/filepath"
 	"path"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -48,6 +50,27 @@ func argFields(args, dump, ipRange string) []string {
 	return f
 }
 
+// ginkgoFields sets the ginkgo focus, skip and flake attempt flags of the
+// e2e.test args when the matching kubetest flags are set, overriding any
+// value args set for them.
+func ginkgoFields(args []string, focus, skip string, flakeAttempts int) []string {
+	f := append([]string{}, args...)
+	set := func(flag, val string) {
+		f, _, _ = util.ExtractField(f, flag)
+		f = append(f, flag+"="+val)
+	}
+	if focus != "" {
+		set("--ginkgo.focus", focus)
+	}
+	if skip != "" {
+		set("--ginkgo.skip", skip)
+	}
+	if flakeAttempts > 0 {
+		set("--ginkgo.flakeAttempts", strconv.Itoa(flakeAttempts))
+	}
+	return f
+}
+
 func run(deploy deployer, o options) error {
 	cmd, err := deploy.KubectlCommand()
 	if err != nil {
@@ -76,8 +99,17 @@ func run(deploy deployer, o options) error {
 		return fmt.Errorf("failed handling --dump-pre-test-logs path: %w", err)
 	}
 
+	cp, err := newCheckpoint(o.checkpointFile, o.resume)
+	if err != nil {
+		return err
+	}
+	if cp.done(phaseUp) {
+		log.Print("Cluster was brought up by a previous run, skipping --up")
+		o.up = false
+	}
+
 	if o.up {
-		if err := control.XMLWrap(&suite, "TearDown Previous", deploy.Down); err != nil {
+		if err := control.XMLWrapPhase(&suite, "down", "TearDown Previous", o.downTimeout, deploy.Down); err != nil {
 			return fmt.Errorf("error tearing down previous cluster: %s", err)
 		}
 	}
@@ -105,7 +137,7 @@ func run(deploy deployer, o options) error {
 		// If we tried to bring the cluster up, make a courtesy
 		// attempt to bring it down so we're not leaving resources around.
 		if o.down {
-			defer control.XMLWrap(&suite, "Deferred TearDown", func() error {
+			defer control.XMLWrapPhase(&suite, "down", "Deferred TearDown", o.downTimeout, func() error {
 				if !downDone {
 					return deploy.Down()
 				}
@@ -113,7 +145,7 @@ func run(deploy deployer, o options) error {
 			})
 		}
 		// Start the cluster using this version.
-		if err := control.XMLWrap(&suite, "Up", deploy.Up); err != nil {
+		if err := upWithRetries(deploy, o); err != nil {
 			if dump != "" {
 				control.XMLWrap(&suite, "DumpClusterLogs (--up failed)", func() error {
 					// This frequently means the cluster does not exist.
@@ -126,6 +158,7 @@ func run(deploy deployer, o options) error {
 			}
 			return fmt.Errorf("starting e2e cluster: %s", err)
 		}
+		errs = util.AppendError(errs, cp.record(phaseUp))
 		// If node testing is enabled, check that the api is reachable before
 		// proceeding with further steps. This is accomplished by listing the nodes.
 		if !o.nodeTests && !strings.EqualFold(string(o.build), "none") {
@@ -145,68 +178,28 @@ func run(deploy deployer, o options) error {
 		}))
 	}
 
-	if o.upgradeArgs != "" {
-		if err := control.XMLWrap(&suite, "test setup", deploy.TestSetup); err != nil {
-			errs = util.AppendError(errs, err)
-		} else {
-			errs = util.AppendError(errs, control.XMLWrap(&suite, "UpgradeTest", func() error {
-				// upgrade tests really only run one spec
-				var env []string
-				for _, v := range os.Environ() {
-					if !strings.HasPrefix(v, "GINKGO_PARALLEL") {
-						env = append(env, v)
-					}
-				}
-				return skewTestEnv(env, argFields(o.upgradeArgs, dump, o.clusterIPRange), "upgrade", o.checkSkew)
-			}))
-		}
-	}
+	errs = append(errs, runUpgradeSteps(deploy, o, dump, cp)...)
 
 	if dumpPreTestLogs != "" {
 		errs = append(errs, dumpRemoteLogs(deploy, o, dumpPreTestLogs, "pre-test")...)
 	}
 
 	testArgs := argFields(o.testArgs, dump, o.clusterIPRange)
-	if o.test {
-		if err := control.XMLWrap(&suite, "test setup", deploy.TestSetup); err != nil {
-			errs = util.AppendError(errs, err)
-		} else {
-			if o.preTestCmd != "" {
-				errs = util.AppendError(errs, control.XMLWrap(&suite, "pre-test command", func() error {
-					cmdLineTokenized := strings.Fields(os.ExpandEnv(o.preTestCmd))
-					return control.FinishRunning(exec.Command(cmdLineTokenized[0], cmdLineTokenized[1:]...))
-				}))
-			}
-			if o.nodeTests {
-				nodeArgs := strings.Fields(o.nodeArgs)
-				errs = util.AppendError(errs, control.XMLWrap(&suite, "Node Tests", func() error {
-					return nodeTest(nodeArgs, o.testArgs, o.nodeTestArgs, o.gcpProject, o.gcpZone, o.runtimeConfig)
-				}))
-			} else if err := control.XMLWrap(&suite, "IsUp", deploy.IsUp); err != nil {
-				errs = util.AppendError(errs, err)
-			} else {
-				if o.deployment != "conformance" {
-					errs = util.AppendError(errs, control.XMLWrap(&suite, "kubectl version", func() error { return getKubectlVersion(deploy) }))
-				}
-
-				if o.skew {
-					errs = util.AppendError(errs, control.XMLWrap(&suite, "SkewTest", func() error {
-						return skewTest(testArgs, "skew", o.checkSkew)
-					}))
-				} else {
-					var tester e2e.Tester
-					tester = &GinkgoScriptTester{}
-					if testBuilder, ok := deploy.(e2e.TestBuilder); ok {
-						tester, err = testBuilder.BuildTester(toBuildTesterOptions(&o))
-						errs = util.AppendError(errs, err)
-					}
-					if tester != nil {
-						errs = util.AppendError(errs, control.XMLWrap(&suite, "Test", func() error {
-							return tester.Run(control, testArgs)
-						}))
-					}
-				}
-			}
+	testsRan := false
+	if o.test && cp.done(phaseTest) {
+		log.Print("Tests passed in a previous run, skipping --test")
+	} else if o.test {
+		testsRan = true
+		errsBeforeTest := len(errs)
+		soak := newSoakLoop(o, dump)
+		for soak.next() {
+			testErrs := testCluster(deploy, o, soak.testArgs(testArgs), soak.suffix())
+			errs = append(errs, testErrs...)
+			errs = util.AppendError(errs, soak.finish(testErrs))
+		}
+		errs = util.AppendError(errs, soak.summarize())
+		if len(errs) == errsBeforeTest {
+			errs = util.AppendError(errs, cp.record(phaseTest))
 		}
 	}
 
@@ -241,6 +234,15 @@ func run(deploy deployer, o options) error {
 		}
 	}
 
+	if o.bazelTest != "" {
+		errs = util.AppendError(errs, control.XMLWrapPhase(&suite, "test", "Bazel Test", o.testTimeout, func() error {
+			if err := deploy.TestSetup(); err != nil {
+				return err
+			}
+			return bazelTest(strings.Fields(o.bazelTest), bazelFlags(o.bazelRemoteCache, o.bazelArgs), dump)
+		}))
+	}
+
 	// TODO: consider remapping charts, etc to testCmd
 
 	var kubemarkWg sync.WaitGroup
@@ -254,8 +256,14 @@ func run(deploy deployer, o options) error {
 		errs = util.AppendError(errs, control.XMLWrap(&suite, "Helm Charts", chartsTest))
 	}
 
-	if dump != "" {
-		errs = append(errs, dumpRemoteLogs(deploy, o, dump, "")...)
+	// Logs dumped by a previous run do not cover tests that ran again, and a
+	// dump of failed tests must be repeated along with the tests on resume.
+	if dump != "" && (testsRan || !cp.done(phaseDump)) {
+		dumpErrs := dumpRemoteLogs(deploy, o, dump, "")
+		if len(dumpErrs) == 0 && (!testsRan || cp.done(phaseTest)) {
+			errs = util.AppendError(errs, cp.record(phaseDump))
+		}
+		errs = append(errs, dumpErrs...)
 	}
 
 	if o.checkLeaks {
@@ -266,13 +274,15 @@ func run(deploy deployer, o options) error {
 	}
 
 	if o.down {
-		errs = util.AppendError(errs, control.XMLWrap(&suite, "TearDown", func() error {
+		errs = util.AppendError(errs, control.XMLWrapPhase(&suite, "down", "TearDown", o.downTimeout, func() error {
 			if !downDone {
 				err := deploy.Down()
 				if err != nil {
 					return err
 				}
 				downDone = true
+				// The cluster is gone, nothing left to resume.
+				return cp.reset()
 			}
 			return nil
 		}))
@@ -358,6 +368,103 @@ func getKubectlVersion(dp deployer) error {
 	}
 }
 
+// upWithRetries brings the cluster up, retrying up to --up-retries times when it fails
+// with an error matching --up-retryable-errors. Partial clusters are torn
+// down before retrying.
+func upWithRetries(deploy deployer, o options) error {
+	backoff := o.upRetryBackoff
+	for attempt := 1; ; attempt++ {
+		name := "Up"
+		if attempt > 1 {
+			name = fmt.Sprintf("Up (attempt %d)", attempt)
+		}
+		err := control.XMLWrapPhase(&suite, "up", name, o.upTimeout, deploy.Up)
+		if err == nil || attempt > o.upRetries {
+			return err
+		}
+		if !retryableUpError(err, o.upRetryableRe) {
+			log.Printf("Not retrying --up, error does not match --up-retryable-errors: %v", err)
+			return err
+		}
+		log.Printf("Retrying --up in %s after attempt %d failed: %v", backoff, attempt, err)
+		if derr := control.XMLWrapPhase(&suite, "down", fmt.Sprintf("TearDown partial cluster (attempt %d)", attempt), o.downTimeout, deploy.Down); derr != nil {
+			log.Printf("Failed to tear down partial cluster, retrying --up anyway: %v", derr)
+		}
+		sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// retryableUpError returns true if the error, or the output of the commands
+// that caused it, matches re.
+func retryableUpError(err error, re *regexp.Regexp) bool {
+	if re == nil {
+		return false
+	}
+	if re.MatchString(err.Error()) {
+		return true
+	}
+	var phaseErr *process.PhaseError
+	return errors.As(err, &phaseErr) && re.MatchString(phaseErr.Output)
+}
+
+// testCluster runs the --test steps against the cluster, suffixing the names
+// of the steps it records with suffix.
+func testCluster(deploy deployer, o options, testArgs []string, suffix string) []error {
+	if err := control.XMLWrap(&suite, "test setup"+suffix, deploy.TestSetup); err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	if o.preTestCmd != "" {
+		errs = util.AppendError(errs, control.XMLWrap(&suite, "pre-test command"+suffix, func() error {
+			cmdLineTokenized := strings.Fields(os.ExpandEnv(o.preTestCmd))
+			return control.FinishRunning(exec.Command(cmdLineTokenized[0], cmdLineTokenized[1:]...))
+		}))
+	}
+	if o.nodeTests {
+		nodeArgs := strings.Fields(o.nodeArgs)
+		errs = util.AppendError(errs, control.XMLWrapPhase(&suite, "test", "Node Tests"+suffix, o.testTimeout, func() error {
+			return nodeTest(nodeArgs, o.testArgs, o.nodeTestArgs, o.gcpProject, o.gcpZone, o.runtimeConfig)
+		}))
+		return errs
+	}
+	if err := control.XMLWrap(&suite, "IsUp"+suffix, deploy.IsUp); err != nil {
+		return util.AppendError(errs, err)
+	}
+	if o.deployment != "conformance" {
+		errs = util.AppendError(errs, control.XMLWrap(&suite, "kubectl version"+suffix, func() error { return getKubectlVersion(deploy) }))
+	}
+
+	if o.skew {
+		errs = util.AppendError(errs, control.XMLWrapPhase(&suite, "test", "SkewTest"+suffix, o.testTimeout, func() error {
+			return skewTest(ginkgoFields(testArgs, o.focusRegex, o.skipRegex, o.ginkgoFlakeAttempts), "skew", o.checkSkew)
+		}))
+		return errs
+	}
+
+	var tester e2e.Tester
+	tester = &GinkgoScriptTester{}
+	// The script tester passes the ginkgo flags through the e2e.test args,
+	// built testers receive them in the BuildTesterOptions instead.
+	runArgs := ginkgoFields(testArgs, o.focusRegex, o.skipRegex, o.ginkgoFlakeAttempts)
+	if testBuilder, ok := deploy.(e2e.TestBuilder); ok {
+		var err error
+		tester, err = testBuilder.BuildTester(toBuildTesterOptions(&o))
+		errs = util.AppendError(errs, err)
+		runArgs = testArgs
+	}
+	if o.conformance && tester != nil {
+		tester = &conformanceTester{tester: tester, dir: conformanceDir(testArgs, o.dump)}
+	}
+	if tester != nil {
+		errs = util.AppendError(errs, control.XMLWrapPhase(&suite, "test", "Test"+suffix, o.testTimeout, func() error {
+			return tester.Run(control, runArgs)
+		}))
+	}
+	return errs
+}
+
 func dumpRemoteLogs(deploy deployer, o options, path, reason string) []error {
 	if o.kubemark {
 		// For dumping kubemark logs with logexporter, we should use
@@ -376,7 +483,7 @@ func dumpRemoteLogs(deploy deployer, o options, path, reason string) []error {
 
 	var errs []error
 
-	errs = util.AppendError(errs, control.XMLWrap(&suite, reason+"DumpClusterLogs", func() error {
+	errs = util.AppendError(errs, control.XMLWrapPhase(&suite, "dump", reason+"DumpClusterLogs", o.dumpTimeout, func() error {
 		return deploy.DumpClusterLogs(path, o.logexporterGCSPath)
 	}))
 
@@ -839,6 +946,8 @@ func toBuildTesterOptions(o *options) *e2e.BuildTesterOptions {
 		FocusRegex:            o.focusRegex,
 		SkipRegex:             o.skipRegex,
 		Parallelism:           o.ginkgoParallel.Get(),
+		FlakeAttempts:         o.ginkgoFlakeAttempts,
 		StorageTestDriverPath: o.storageTestDriverPath,
+		Conformance:           o.conformance,
 	}
 }
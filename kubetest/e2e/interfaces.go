@@ -37,4 +37,11 @@ type BuildTesterOptions struct {
 	SkipRegex             string
 	StorageTestDriverPath string
 	Parallelism           int
+	// FlakeAttempts is how many times ginkgo runs a failing test, zero for the tester's default.
+	FlakeAttempts int
+	// Conformance locks the tester to the conformance suite, see ConformanceFocus.
+	Conformance bool
 }
+
+// ConformanceFocus selects the tests a conformance submission runs.
+const ConformanceFocus = `\[Conformance\]`
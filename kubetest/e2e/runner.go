@@ -80,6 +80,16 @@ func NewGinkgoTester(o *BuildTesterOptions) *GinkgoTester {
 	t.GinkgoParallel = o.Parallelism
 	t.FocusRegex = o.FocusRegex
 	t.SkipRegex = o.SkipRegex
+	if o.FlakeAttempts > 0 {
+		t.FlakeAttempts = o.FlakeAttempts
+	}
+	if o.Conformance {
+		// Conformance results must come from a single serial run of the whole suite.
+		t.FocusRegex = ConformanceFocus
+		t.SkipRegex = ""
+		t.FlakeAttempts = 1
+		t.GinkgoParallel = 1
+	}
 
 	return t
 }
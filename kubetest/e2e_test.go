@@ -0,0 +1,215 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"os/exec"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"k8s.io/test-infra/kubetest/process"
+)
+
+func TestGinkgoFields(t *testing.T) {
+	cases := []struct {
+		name          string
+		args          []string
+		focus         string
+		skip          string
+		flakeAttempts int
+		expected      []string
+	}{
+		{
+			name:     "nothing set keeps args",
+			args:     []string{"--report-dir=/tmp", "--ginkgo.focus=foo"},
+			expected: []string{"--report-dir=/tmp", "--ginkgo.focus=foo"},
+		},
+		{
+			name:          "flags are appended",
+			args:          []string{"--report-dir=/tmp"},
+			focus:         `\[Conformance\]`,
+			skip:          "Serial|Slow",
+			flakeAttempts: 2,
+			expected: []string{
+				"--report-dir=/tmp",
+				`--ginkgo.focus=\[Conformance\]`,
+				"--ginkgo.skip=Serial|Slow",
+				"--ginkgo.flakeAttempts=2",
+			},
+		},
+		{
+			name:     "flags override args",
+			args:     []string{"--ginkgo.focus=foo", "--ginkgo.skip", "bar", "--report-dir=/tmp"},
+			focus:    "baz",
+			skip:     "qux",
+			expected: []string{"--report-dir=/tmp", "--ginkgo.focus=baz", "--ginkgo.skip=qux"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := ginkgoFields(tc.args, tc.focus, tc.skip, tc.flakeAttempts)
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRetryableUpError(t *testing.T) {
+	re := regexp.MustCompile(defaultUpRetryableErrors)
+	cases := []struct {
+		name     string
+		err      error
+		re       *regexp.Regexp
+		expected bool
+	}{
+		{
+			name:     "quota in error",
+			err:      errors.New("Quota 'CPUS' exceeded. Limit: 24.0 in region us-central1."),
+			re:       re,
+			expected: true,
+		},
+		{
+			name: "rate limit in output",
+			err: &process.PhaseError{
+				Phase:  "up",
+				Output: "ERROR: (gcloud.container.clusters.create) Rate Limit Exceeded",
+				Err:    errors.New("error during gcloud container clusters create: exit status 1"),
+			},
+			re:       re,
+			expected: true,
+		},
+		{
+			name:     "other error",
+			err:      errors.New("error during ./hack/e2e-internal/e2e-up.sh: exit status 1"),
+			re:       re,
+			expected: false,
+		},
+		{
+			name:     "no retries",
+			err:      errors.New("Quota exceeded"),
+			expected: false,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := retryableUpError(tc.err, tc.re); actual != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+// fakeUpDeployer fails its first Up calls with upErrs.
+type fakeUpDeployer struct {
+	upErrs []error
+	ups    int
+	downs  int
+}
+
+func (f *fakeUpDeployer) Up() error {
+	f.ups++
+	if f.ups <= len(f.upErrs) {
+		return f.upErrs[f.ups-1]
+	}
+	return nil
+}
+func (f *fakeUpDeployer) IsUp() error                                 { return nil }
+func (f *fakeUpDeployer) DumpClusterLogs(_, _ string) error           { return nil }
+func (f *fakeUpDeployer) TestSetup() error                            { return nil }
+func (f *fakeUpDeployer) Down() error                                 { f.downs++; return nil }
+func (f *fakeUpDeployer) GetClusterCreated(string) (time.Time, error) { return time.Time{}, nil }
+func (f *fakeUpDeployer) KubectlCommand() (*exec.Cmd, error)          { return nil, nil }
+
+func TestUpWithRetries(t *testing.T) {
+	oldSleep := sleep
+	defer func() { sleep = oldSleep }()
+
+	quota := errors.New("Quota 'CPUS' exceeded")
+	quotaAgain := errors.New("Quota 'IN_USE_ADDRESSES' exceeded")
+	other := errors.New("error during ./hack/e2e-internal/e2e-up.sh: exit status 1")
+	cases := []struct {
+		name     string
+		retries  int
+		upErrs   []error
+		expected error
+		ups      int
+		sleeps   []time.Duration
+	}{
+		{
+			name: "up passes",
+			ups:  1,
+		},
+		{
+			name:     "no retries",
+			upErrs:   []error{quota},
+			expected: quota,
+			ups:      1,
+		},
+		{
+			name:    "retryable errors are retried",
+			retries: 3,
+			upErrs:  []error{quota, quotaAgain},
+			ups:     3,
+			sleeps:  []time.Duration{time.Minute, 2 * time.Minute},
+		},
+		{
+			name:     "other errors are not retried",
+			retries:  3,
+			upErrs:   []error{quota, other},
+			expected: other,
+			ups:      2,
+			sleeps:   []time.Duration{time.Minute},
+		},
+		{
+			name:     "stops after the retries with the last error",
+			retries:  2,
+			upErrs:   []error{quota, quota, quotaAgain},
+			expected: quotaAgain,
+			ups:      3,
+			sleeps:   []time.Duration{time.Minute, 2 * time.Minute},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sleeps []time.Duration
+			sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+			d := &fakeUpDeployer{upErrs: tc.upErrs}
+			o := options{
+				upRetries:      tc.retries,
+				upRetryBackoff: time.Minute,
+				upRetryableRe:  regexp.MustCompile(defaultUpRetryableErrors),
+			}
+
+			err := upWithRetries(d, o)
+			if tc.expected == nil && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if tc.expected != nil && !errors.Is(err, tc.expected) {
+				t.Errorf("expected error %v, got %v", tc.expected, err)
+			}
+			if d.ups != tc.ups || d.downs != len(tc.sleeps) {
+				t.Errorf("expected %d ups and %d downs, got %d and %d", tc.ups, len(tc.sleeps), d.ups, d.downs)
+			}
+			if !reflect.DeepEqual(sleeps, tc.sleeps) {
+				t.Errorf("expected backoffs %v, got %v", tc.sleeps, sleeps)
+			}
+		})
+	}
+}
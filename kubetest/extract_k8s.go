@@ -18,8 +18,11 @@ package main
 
 import (
 	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -39,11 +42,11 @@ type extractMode int
 const (
 	_          extractMode = iota
 	localBazel             // local bazel
-	local                  // local
+	local                  // local, local=PATH
 	gci                    // gci/FAMILY, gci/FAMILY?project=IMAGE_PROJECT:k8s-map-bucket=BUCKET_NAME
 	gciCi                  // gci/FAMILY/CI_VERSION
 	gke                    // gke(deprecated), gke-default, gke-latest, gke-channel-CHANNEL_NAME
-	ci                     // ci/latest, ci/latest-1.5
+	ci                     // ci/latest, ci/latest-1.5, ci/latest-green
 	ciFast                 // ci/latest-fast, ci/latest-1.19-fast
 	rc                     // release/latest, release/latest-1.5
 	stable                 // release/stable, release/stable-1.5
@@ -73,8 +76,8 @@ func (l *extractStrategies) String() string {
 // Converts --extract=release/stable, etc into an extractStrategy{}
 func (l *extractStrategies) Set(value string) error {
 	var strategies = map[string]extractMode{
-		`^(bazel)$`: localBazel,
-		`^(local)`:  local,
+		`^(bazel)$`:           localBazel,
+		`^(local)(?:=(.+))?$`: local,
 		`^gke-?(default|channel-(rapid|regular|stable)|latest(-\d+.\d+(.\d+(-gke)?)?)?)?$`: gke,
 		`^gci/([\w-]+(?:\?{1}(?::?[\w-]+=[\w-]+)+)?)$`:                                     gci,
 		`^gci/([\w-]+(?:\?{1}(?::?[\w-]+=[\w-]+)+)?)/(.+)$`:                                gciCi,
@@ -194,8 +197,9 @@ func ensureKube() (string, error) {
 	return f.Name(), nil
 }
 
-// Download named binaries for kubernetes
-func getNamedBinaries(url, version, tarball string, retry int) error {
+// Download a named tarball for kubernetes into the current directory,
+// verifying it against its published checksum when --extract-verify is set.
+func downloadTarball(url, version, tarball string, retry int) error {
 	f, err := os.Create(tarball)
 	if err != nil {
 		return err
@@ -205,7 +209,13 @@ func getNamedBinaries(url, version, tarball string, retry int) error {
 
 	for i := 0; i < retry; i++ {
 		log.Printf("downloading %v from %v", tarball, full)
-		if err := httpRead(full, f); err == nil {
+		if err = f.Truncate(0); err != nil {
+			return err
+		}
+		if _, err = f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if err = httpRead(full, f); err == nil {
 			break
 		}
 		err = fmt.Errorf("url=%s version=%s failed get %v: %w", url, version, tarball, err)
@@ -216,8 +226,50 @@ func getNamedBinaries(url, version, tarball string, retry int) error {
 		sleep(time.Duration(i) * time.Second)
 	}
 
-	f.Close()
-	o, err := control.Output(exec.Command("md5sum", f.Name()))
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if extractVerify {
+		return verifyChecksum(f.Name(), full+".sha512")
+	}
+	return nil
+}
+
+// Returns an error unless the sha512 of file matches the checksum published at sumURL.
+func verifyChecksum(file, sumURL string) error {
+	b, err := httpCat(sumURL)
+	if err != nil {
+		return fmt.Errorf("failed to get checksum from %s: %w", sumURL, err)
+	}
+	fields := strings.Fields(string(b))
+	if len(fields) == 0 {
+		return fmt.Errorf("empty checksum at %s", sumURL)
+	}
+	want := strings.ToLower(fields[0])
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := sha512.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		return fmt.Errorf("%s has sha512 %s, but %s wants %s", file, got, sumURL, want)
+	}
+	log.Printf("Verified sha512 of %s", file)
+	return nil
+}
+
+// Download and extract named binaries for kubernetes
+func getNamedBinaries(url, version, tarball string, retry int) error {
+	if err := downloadTarball(url, version, tarball, retry); err != nil {
+		return err
+	}
+
+	o, err := control.Output(exec.Command("md5sum", tarball))
 	if err != nil {
 		return err
 	}
@@ -227,9 +279,9 @@ func getNamedBinaries(url, version, tarball string, retry int) error {
 	if err != nil {
 		return fmt.Errorf("unable to get current directory: %w", err)
 	}
-	log.Printf("Extracting tar file %v into directory %v", f.Name(), cwd)
+	log.Printf("Extracting tar file %v into directory %v", tarball, cwd)
 
-	if err = control.FinishRunning(exec.Command("tar", "-xzf", f.Name())); err != nil {
+	if err = control.FinishRunning(exec.Command("tar", "-xzf", tarball)); err != nil {
 		return err
 	}
 	return nil
@@ -237,8 +289,101 @@ func getNamedBinaries(url, version, tarball string, retry int) error {
 
 var (
 	sleep = time.Sleep
+
+	// Set from --extract-cache and --extract-verify before extracting.
+	extractCache  releaseCache
+	extractVerify bool
 )
 
+// releaseCache keeps extracted kubernetes trees under dir, keyed by the
+// release url and version, so repeated extractions on a host skip the download.
+type releaseCache struct {
+	dir string
+}
+
+// Returns the cache entry for url and version, or "" when it cannot be cached.
+func (c releaseCache) path(url, version string) string {
+	if c.dir == "" {
+		return ""
+	}
+	parts := strings.SplitN(url, "://", 2)
+	if len(parts) != 2 || parts[0] == "file" {
+		// Local releases gain nothing from a copy.
+		return ""
+	}
+	return filepath.Join(c.dir, filepath.FromSlash(parts[1]), version)
+}
+
+// Copies a cached kubernetes tree into the current directory, returning false on a miss.
+func (c releaseCache) restore(url, version string) (bool, error) {
+	p := c.path(url, version)
+	if p == "" {
+		return false, nil
+	}
+	if _, err := os.Stat(filepath.Join(p, "kubernetes")); os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	log.Printf("Restoring %s from extract cache %s", version, p)
+	if err := control.FinishRunning(exec.Command("cp", "-a", filepath.Join(p, "kubernetes"), ".")); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Copies the kubernetes tree in the current directory into the cache.
+func (c releaseCache) save(url, version string) error {
+	p := c.path(url, version)
+	if p == "" {
+		return nil
+	}
+	if _, err := os.Stat(p); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	// Copy into a temporary sibling and rename so concurrent jobs never see a partial entry.
+	tmp, err := ioutil.TempDir(filepath.Dir(p), ".extract")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	log.Printf("Saving %s to extract cache %s", version, p)
+	if err := control.FinishRunning(exec.Command("cp", "-a", "kubernetes", tmp)); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		if _, serr := os.Stat(p); serr == nil {
+			return nil // Another job won the race.
+		}
+		return err
+	}
+	return nil
+}
+
+func setReleaseEnv(url, version string) error {
+	if err := os.Setenv("KUBERNETES_RELEASE_URL", url); err != nil {
+		return err
+	}
+
+	if err := os.Setenv("KUBERNETES_RELEASE", version); err != nil {
+		return err
+	}
+	if err := os.Setenv("KUBERNETES_SKIP_CONFIRM", "y"); err != nil {
+		return err
+	}
+	if err := os.Setenv("KUBERNETES_SKIP_CREATE_CLUSTER", "y"); err != nil {
+		return err
+	}
+	if err := os.Setenv("KUBERNETES_DOWNLOAD_TESTS", "y"); err != nil {
+		return err
+	}
+	// kube-up in cluster/gke/util.sh depends on this
+	return os.Setenv("CLUSTER_API_VERSION", version[1:])
+}
+
 // Calls KUBERNETES_RELEASE_URL=url KUBERNETES_RELEASE=version get-kube.sh.
 // This will download version from the specified url subdir and extract
 // the tarballs.
@@ -266,31 +411,24 @@ var getKube = func(url, version string, getSrc bool) error {
 		if err := getNamedBinaries(url, version, "kubernetes-src.tar.gz", 3); err != nil {
 			return err
 		}
+	} else if ok, err := extractCache.restore(url, version); err != nil {
+		return err
+	} else if ok {
+		return setReleaseEnv(url, version)
 	}
 
 	k, err := ensureKube()
 	if err != nil {
 		return err
 	}
-	if err := os.Setenv("KUBERNETES_RELEASE_URL", url); err != nil {
-		return err
-	}
-
-	if err := os.Setenv("KUBERNETES_RELEASE", version); err != nil {
-		return err
-	}
-	if err := os.Setenv("KUBERNETES_SKIP_CONFIRM", "y"); err != nil {
-		return err
-	}
-	if err := os.Setenv("KUBERNETES_SKIP_CREATE_CLUSTER", "y"); err != nil {
-		return err
-	}
-	if err := os.Setenv("KUBERNETES_DOWNLOAD_TESTS", "y"); err != nil {
+	if err := setReleaseEnv(url, version); err != nil {
 		return err
 	}
-	// kube-up in cluster/gke/util.sh depends on this
-	if err := os.Setenv("CLUSTER_API_VERSION", version[1:]); err != nil {
-		return err
+	if extractVerify && strings.HasPrefix(url, "http") {
+		// get-kube.sh reuses a kubernetes.tar.gz of the right version in pwd.
+		if err := downloadTarball(url, version, "kubernetes.tar.gz", 3); err != nil {
+			return err
+		}
 	}
 	log.Printf("U=%s R=%s get-kube.sh", url, version)
 	for i := 0; i < 5; i++ {
@@ -302,8 +440,13 @@ var getKube = func(url, version string, getSrc bool) error {
 		log.Println(err)
 		sleep(time.Duration(i) * time.Second)
 	}
-
-	return err
+	if err != nil {
+		return err
+	}
+	if !getSrc {
+		return extractCache.save(url, version)
+	}
+	return nil
 }
 
 // wrapper for gsutil cat
@@ -425,6 +568,27 @@ func setReleaseFromGci(image, k8sMapBucket, releaseBucket string, getSrc bool) e
 	return getKube(fmt.Sprintf("https://storage.googleapis.com/%s/release", releaseBucket), strings.TrimSpace(r), getSrc)
 }
 
+// Finds the gcs-stage directory of a local build, given either a kubernetes
+// checkout, its _output tree or the gcs-stage directory itself.
+func localStage(root string) (string, error) {
+	if root == "" {
+		return util.K8s("kubernetes", "_output", "gcs-stage"), nil
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	for _, p := range []string{
+		filepath.Join(root, "_output", "gcs-stage"),
+		filepath.Join(root, "gcs-stage"),
+	} {
+		if i, err := os.Stat(p); err == nil && i.IsDir() {
+			return p, nil
+		}
+	}
+	return root, nil
+}
+
 func (e extractStrategy) Extract(project, zone, region, ciBucket, releaseBucket string, extractSrc bool) error {
 	switch e.mode {
 	case localBazel:
@@ -444,7 +608,10 @@ func (e extractStrategy) Extract(project, zone, region, ciBucket, releaseBucket
 		}
 		return getKube(fmt.Sprintf("file://%s", root), version, extractSrc)
 	case local:
-		url := util.K8s("kubernetes", "_output", "gcs-stage")
+		url, err := localStage(e.ciVersion)
+		if err != nil {
+			return err
+		}
 		files, err := ioutil.ReadDir(url)
 		if err != nil {
 			return err
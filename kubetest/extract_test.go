@@ -17,14 +17,19 @@ limitations under the License.
 package main
 
 import (
+	"crypto/sha512"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
+
+	"k8s.io/test-infra/kubetest/process"
 )
 
 func TestParseGciExtractOption(t *testing.T) {
@@ -179,6 +184,16 @@ func TestExtractStrategies(t *testing.T) {
 			"https://storage.googleapis.com/k8s-release-dev/ci",
 			"v1.2.3+abcde",
 		},
+		{
+			"ci/latest-green",
+			"https://storage.googleapis.com/k8s-release-dev/ci",
+			"v1.2.3+abcde",
+		},
+		{
+			"release/stable-1.19",
+			"https://storage.googleapis.com/k8s-release/release",
+			"v1.2.3+abcde",
+		},
 		{
 			"ci/latest-fast",
 			"https://storage.googleapis.com/k8s-release-dev/ci/fast",
@@ -388,3 +403,189 @@ func TestGciExtractStrategy(t *testing.T) {
 		}
 	}
 }
+
+func TestLocalExtractStrategy(t *testing.T) {
+	root, err := ioutil.TempDir("", "extract-local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	stage := filepath.Join(root, "_output", "gcs-stage")
+	if err := os.MkdirAll(filepath.Join(stage, "v1.2.3+abcde"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		option    string
+		expectURL string
+	}{
+		{"local=" + root, "file://" + stage},
+		{"local=" + filepath.Join(root, "_output"), "file://" + stage},
+		{"local=" + stage, "file://" + stage},
+	}
+
+	var gotURL string
+	var gotVersion string
+	oldGetKube := getKube
+	defer func() { getKube = oldGetKube }()
+	getKube = func(url, version string, _ bool) error {
+		gotURL = url
+		gotVersion = version
+		os.Mkdir("kubernetes", 0775)
+		return nil
+	}
+
+	if o, err := os.Getwd(); err != nil {
+		t.Fatal(err)
+	} else {
+		defer os.Chdir(o)
+	}
+	for _, tc := range cases {
+		if d, err := ioutil.TempDir("", "extract"); err != nil {
+			t.Fatal(err)
+		} else if err := os.Chdir(d); err != nil {
+			t.Fatal(err)
+		}
+
+		var es extractStrategies
+		if err := es.Set(tc.option); err != nil {
+			t.Errorf("extractStrategy.Set(%q) returned err: %q", tc.option, err)
+			continue
+		}
+		if err := es.Extract("", "", "", "", "", false); err != nil {
+			t.Errorf("extractStrategy(%q).Extract() returned err: %q", tc.option, err)
+		}
+		if gotURL != tc.expectURL || gotVersion != "v1.2.3+abcde" {
+			t.Errorf("extractStrategy(%q).Extract() wanted getKube(%q, %q), got getKube(%q, %q)", tc.option, tc.expectURL, "v1.2.3+abcde", gotURL, gotVersion)
+		}
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	f, err := ioutil.TempFile("", "kubernetes.tar.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString("tarball"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	sum := sha512.Sum512([]byte("tarball"))
+	good := hex.EncodeToString(sum[:])
+
+	cases := []struct {
+		name      string
+		published string
+		success   bool
+	}{
+		{
+			name:      "matching checksum",
+			published: good,
+			success:   true,
+		},
+		{
+			name:      "matching checksum with file name",
+			published: strings.ToUpper(good) + "  kubernetes.tar.gz\n",
+			success:   true,
+		},
+		{
+			name:      "mismatched checksum",
+			published: strings.Repeat("0", len(good)),
+		},
+		{
+			name: "empty checksum",
+		},
+	}
+
+	oldHTTPCat := httpCat
+	defer func() { httpCat = oldHTTPCat }()
+	for _, tc := range cases {
+		httpCat = func(url string) ([]byte, error) {
+			if url != "https://example.com/kubernetes.tar.gz.sha512" {
+				return nil, fmt.Errorf("unexpected url %s", url)
+			}
+			return []byte(tc.published), nil
+		}
+		err := verifyChecksum(f.Name(), "https://example.com/kubernetes.tar.gz.sha512")
+		if tc.success && err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+		if !tc.success && err == nil {
+			t.Errorf("%s: unexpectedly succeeded", tc.name)
+		}
+	}
+}
+
+func TestReleaseCache(t *testing.T) {
+	oldControl := control
+	defer func() { control = oldControl }()
+	control = process.NewControl(time.Hour, time.NewTimer(time.Hour), time.NewTimer(time.Hour), false)
+
+	dir, err := ioutil.TempDir("", "extract-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	c := releaseCache{dir: dir}
+	url := "https://storage.googleapis.com/k8s-release-dev/ci"
+
+	if p := (releaseCache{}).path(url, "v1.2.3"); p != "" {
+		t.Errorf("disabled cache has path %q", p)
+	}
+	if p := c.path("file:///tmp/gcs-stage", "v1.2.3"); p != "" {
+		t.Errorf("local release has cache path %q", p)
+	}
+	if p, want := c.path(url, "v1.2.3"), filepath.Join(dir, "storage.googleapis.com", "k8s-release-dev", "ci", "v1.2.3"); p != want {
+		t.Errorf("path() = %q, want %q", p, want)
+	}
+
+	if o, err := os.Getwd(); err != nil {
+		t.Fatal(err)
+	} else {
+		defer os.Chdir(o)
+	}
+	src, err := ioutil.TempDir("", "extract-src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+	if err := os.Chdir(src); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := c.restore(url, "v1.2.3"); err != nil || ok {
+		t.Fatalf("restore() on empty cache = %t, %v", ok, err)
+	}
+	if err := os.MkdirAll(filepath.Join("kubernetes", "server"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join("kubernetes", "version"), []byte("v1.2.3"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.save(url, "v1.2.3"); err != nil {
+		t.Fatalf("save() failed: %v", err)
+	}
+	// Saving again is a no-op.
+	if err := c.save(url, "v1.2.3"); err != nil {
+		t.Fatalf("second save() failed: %v", err)
+	}
+
+	dst, err := ioutil.TempDir("", "extract-dst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dst)
+	if err := os.Chdir(dst); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := c.restore(url, "v1.2.3"); err != nil || !ok {
+		t.Fatalf("restore() = %t, %v", ok, err)
+	}
+	if b, err := ioutil.ReadFile(filepath.Join("kubernetes", "version")); err != nil || string(b) != "v1.2.3" {
+		t.Errorf("restored version = %q, %v", b, err)
+	}
+	if ok, err := c.restore(url, "v1.2.4"); err != nil || ok {
+		t.Errorf("restore() of another version = %t, %v", ok, err)
+	}
+}
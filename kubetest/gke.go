@@ -48,7 +48,7 @@ var (
 	gkeAdditionalZones             = flag.String("gke-additional-zones", "", "(gke only) List of additional Google Compute Engine zones to use. Clusters are created symmetrically across zones by default, see --gke-shape for details.")
 	gkeNodeLocations               = flag.String("gke-node-locations", "", "(gke only) List of Google Compute Engine zones to use.")
 	gkeEnvironment                 = flag.String("gke-environment", "", "(gke only) Container API endpoint to use, one of 'test', 'staging', 'prod', or a custom https:// URL")
-	gkeShape                       = flag.String("gke-shape", `{"default":{"Nodes":3,"MachineType":"n1-standard-2"}}`, `(gke only) A JSON description of node pools to create. The node pool 'default' is required and used for initial cluster creation. All node pools are symmetric across zones, so the cluster total node count is {total nodes in --gke-shape} * {1 + (length of --gke-additional-zones)}. Example: '{"default":{"Nodes":999,"MachineType:":"n1-standard-1"},"heapster":{"Nodes":1, "MachineType":"n1-standard-8", "ExtraArgs": []}}`)
+	gkeShape                       = flag.String("gke-shape", `{"default":{"Nodes":3,"MachineType":"n1-standard-2"}}`, `(gke only) A JSON description of node pools to create. The node pool 'default' is required and used for initial cluster creation. All node pools are symmetric across zones, so the cluster total node count is {total nodes in --gke-shape} * {1 + (length of --gke-additional-zones)}. Each pool may also set node Labels (a map) and Taints (a list of key=value:effect). Example: '{"default":{"Nodes":999,"MachineType:":"n1-standard-1"},"heapster":{"Nodes":1, "MachineType":"n1-standard-8", "Labels":{"dedicated":"heapster"}, "Taints":["dedicated=heapster:NoSchedule"], "ExtraArgs": []}}`)
 	gkeCreateArgs                  = flag.String("gke-create-args", "", "(gke only) (deprecated, use a modified --gke-create-command') Additional arguments passed directly to 'gcloud container clusters create'")
 	gkeCommandGroup                = flag.String("gke-command-group", "", "(gke only) Use a different gcloud track (e.g. 'alpha') for all 'gcloud container' commands. Note: This is added to --gke-create-command on create. You should only use --gke-command-group if you need to change the gcloud track for *every* gcloud container command.")
 	gkeGcloudCommand               = flag.String("gke-gcloud-command", "gcloud", "(gke only) gcloud command used to create a cluster. Modify if you need to pass custom gcloud to create cluster.")
@@ -65,6 +65,7 @@ var (
 	gkeNatMinPortsPerVm            = flag.Int("gke-nat-min-ports-per-vm", 64, "(gke only) Specify number of ports per cluster VM for NAT router. Number of ports * number of nodes / 64k = number of auto-allocated IP addresses (there is a hard limit of 100 IPs).")
 	gkeDownTimeout                 = flag.Duration("gke-down-timeout", 1*time.Hour, "(gke only) Timeout for gcloud container clusters delete call. Defaults to 1 hour which matches gcloud's default.")
 	gkeRemoveNetwork               = flag.Bool("gke-remove-network", true, "(gke only) At the end of the test remove non-default network that was used by cluster.")
+	gkeEnableAlphaFeatures         = flag.Bool("gke-enable-alpha-features", false, "(gke only) Create an alpha cluster with all Kubernetes alpha APIs and features enabled. Node auto-repair and auto-upgrade are disabled as alpha clusters don't support them.")
 	gkeDumpConfigMaps              = flag.String("gke-dump-configmaps", "[]", `(gke-only) A JSON description of ConfigMaps to dump as part of gathering cluster logs. Note: --dump or --dump-pre-test-logs flags must also be set. Example: '[{"Name":"my-map", "Namespace":"default", "DataKey":"my-data-key"}]`)
 
 	// poolReTemplate matches instance group URLs of the form `https://www.googleapis.com/compute/v1/projects/some-project/zones/a-zone/instanceGroupManagers/gke-some-cluster-some-pool-90fcb815-grp`. Match meaning:
@@ -80,9 +81,32 @@ var (
 type gkeNodePool struct {
 	Nodes       int
 	MachineType string
+	Labels      map[string]string
+	Taints      []string
 	ExtraArgs   []string
 }
 
+// args returns the gcloud arguments configuring the machine type, node
+// labels and node taints of the pool, followed by its ExtraArgs.
+func (p gkeNodePool) args() []string {
+	var args []string
+	if p.MachineType != "" {
+		args = append(args, "--machine-type="+p.MachineType)
+	}
+	if len(p.Labels) > 0 {
+		var labels []string
+		for k, v := range p.Labels {
+			labels = append(labels, k+"="+v)
+		}
+		sort.Strings(labels)
+		args = append(args, "--node-labels="+strings.Join(labels, ","))
+	}
+	if len(p.Taints) > 0 {
+		args = append(args, "--node-taints="+strings.Join(p.Taints, ","))
+	}
+	return append(args, p.ExtraArgs...)
+}
+
 type gkeConfigMap struct {
 	Name      string
 	Namespace string
@@ -105,6 +129,7 @@ type gkeDeployer struct {
 	subnetMode                  string
 	subnetworkRegion            string
 	createNat                   bool
+	enableAlphaFeatures         bool
 	natMinPortsPerVm            int
 	image                       string
 	imageFamily                 string
@@ -185,6 +210,7 @@ func newGKE(provider, project, zone, region, network, image, imageFamily, imageP
 	g.nodeLocations = *gkeNodeLocations
 	g.nodePorts = *gkeNodePorts
 	g.createNat = *gkeCreateNat
+	g.enableAlphaFeatures = *gkeEnableAlphaFeatures
 	g.natMinPortsPerVm = *gkeNatMinPortsPerVm
 
 	err = json.Unmarshal([]byte(*gkeShape), &g.shape)
@@ -363,13 +389,13 @@ func (g *gkeDeployer) Up() error {
 	if def.Nodes > 0 {
 		args = append(args, "--num-nodes="+strconv.Itoa(def.Nodes))
 	}
-	if def.MachineType != "" {
-		args = append(args, "--machine-type="+def.MachineType)
-	}
 	if g.image != "" {
 		args = append(args, "--image-type="+g.image)
 	}
-	args = append(args, def.ExtraArgs...)
+	args = append(args, def.args()...)
+	if g.enableAlphaFeatures {
+		args = append(args, "--enable-kubernetes-alpha", "--no-enable-autorepair", "--no-enable-autoupgrade")
+	}
 	if strings.ToUpper(g.image) == "CUSTOM" {
 		args = append(args, "--image-family="+g.imageFamily)
 		args = append(args, "--image-project="+g.imageProject)
@@ -422,10 +448,10 @@ func (g *gkeDeployer) Up() error {
 			"--project=" + g.project,
 			g.location,
 			"--num-nodes=" + strconv.Itoa(pool.Nodes)}
-		if pool.MachineType != "" {
-			poolArgs = append(poolArgs, "--machine-type="+pool.MachineType)
+		poolArgs = append(poolArgs, pool.args()...)
+		if g.enableAlphaFeatures {
+			poolArgs = append(poolArgs, "--no-enable-autorepair", "--no-enable-autoupgrade")
 		}
-		poolArgs = append(poolArgs, pool.ExtraArgs...)
 		if err := control.FinishRunning(exec.Command("gcloud", g.containerArgs(poolArgs...)...)); err != nil {
 			return fmt.Errorf("error creating node pool %q: %w", poolName, err)
 		}
@@ -1018,3 +1044,5 @@ func (g *gkeDeployer) GetClusterCreated(gcpProject string) (time.Time, error) {
 }
 
 func (g *gkeDeployer) KubectlCommand() (*exec.Cmd, error) { return nil, nil }
+
+func (g *gkeDeployer) kubeconfigPath() string { return g.kubecfg }
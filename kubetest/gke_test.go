@@ -180,3 +180,44 @@ func TestParseInstanceGroupsFromGcloud(t *testing.T) {
 		})
 	}
 }
+
+func TestNodePoolArgs(t *testing.T) {
+	cases := []struct {
+		name     string
+		pool     gkeNodePool
+		expected []string
+	}{
+		{
+			name: "Empty pool",
+			pool: gkeNodePool{Nodes: 3},
+		},
+		{
+			name: "Machine type and extra args",
+			pool: gkeNodePool{Nodes: 1, MachineType: "n1-standard-8", ExtraArgs: []string{"--preemptible"}},
+			expected: []string{
+				"--machine-type=n1-standard-8",
+				"--preemptible",
+			},
+		},
+		{
+			name: "Labels are sorted and taints joined",
+			pool: gkeNodePool{
+				Nodes:  1,
+				Labels: map[string]string{"zeta": "z", "alpha": "a"},
+				Taints: []string{"dedicated=gpu:NoSchedule", "spot=true:PreferNoSchedule"},
+			},
+			expected: []string{
+				"--node-labels=alpha=a,zeta=z",
+				"--node-taints=dedicated=gpu:NoSchedule,spot=true:PreferNoSchedule",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := tc.pool.args(); !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("Not equal: %v %v", actual, tc.expected)
+			}
+		})
+	}
+}
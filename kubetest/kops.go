@@ -24,6 +24,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"math/rand"
@@ -86,7 +87,10 @@ var (
 	kopsOverrides    = flag.String("kops-overrides", "", "(kops only) List of Kops cluster configuration overrides, comma delimited.")
 	kopsFeatureFlags = flag.String("kops-feature-flags", "", "(kops only) List of Kops feature flags to enable, comma delimited.")
 
-	kopsMultipleZones = flag.Bool("kops-multiple-zones", false, "(kops only) run tests in multiple zones")
+	kopsMultipleZones  = flag.Bool("kops-multiple-zones", false, "(kops only) run tests in multiple zones")
+	kopsMasterZones    = flag.String("kops-master-zones", "", "(kops only) zones to spread masters across, comma delimited. Defaults to the first --kops-master-count zones of --kops-zones when running more than one master.")
+	kopsInstanceGroups = flag.String("kops-instance-groups", "", `(kops only) A JSON description of additional node instance groups to create after the cluster. Example: '{"gpu":{"MachineType":"p2.xlarge","MinSize":1,"MaxSize":1,"Zones":["us-east-1a"],"NodeLabels":{"accelerator":"gpu"}}}'. Zones default to the cluster zones.`)
+	kopsValidateWait   = flag.Duration("kops-validate-wait", 15*time.Minute, "(kops only) Time to wait for 'kops validate cluster' to succeed before handing the cluster off to tests.")
 
 	awsRegions = []string{
 		"ap-south-1",
@@ -109,6 +113,17 @@ var (
 	}
 )
 
+// kopsInstanceGroup describes an additional node instance group, see
+// --kops-instance-groups.
+type kopsInstanceGroup struct {
+	MachineType string
+	MinSize     int
+	MaxSize     int
+	Zones       []string
+	NodeLabels  map[string]string
+	Image       string
+}
+
 type kops struct {
 	path        string
 	kubeVersion string
@@ -163,6 +178,15 @@ type kops struct {
 
 	// multipleZones denotes using more than one zone
 	multipleZones bool
+
+	// masterZones are the zones masters are spread across
+	masterZones []string
+
+	// instanceGroups are additional node instance groups, keyed by name
+	instanceGroups map[string]kopsInstanceGroup
+
+	// validateWait is how long to wait for kops validate cluster to succeed
+	validateWait time.Duration
 }
 
 var _ deployer = kops{}
@@ -304,6 +328,16 @@ func newKops(provider, gcpProject, cluster string) (*kops, error) {
 
 	log.Printf("executing kops with zones: %q", zones)
 
+	masterZones, err := kopsMasterZoneList(*kopsMasterZones, zones, *kopsMasterCount)
+	if err != nil {
+		return nil, err
+	}
+
+	instanceGroups, err := parseKopsInstanceGroups(*kopsInstanceGroups, zones)
+	if err != nil {
+		return nil, err
+	}
+
 	// Set kops-base-url from kops-version
 	if *kopsVersion != "" {
 		if *kopsBaseURL != "" {
@@ -378,9 +412,158 @@ func newKops(provider, gcpProject, cluster string) (*kops, error) {
 		networkMode:   *kopsNetworkMode,
 		overrides:     *kopsOverrides,
 		featureFlags:  *kopsFeatureFlags,
+		masterZones:   masterZones,
+		validateWait:  *kopsValidateWait,
+
+		instanceGroups: instanceGroups,
 	}, nil
 }
 
+// kopsMasterZoneList determines the zones to run masters in. Explicitly
+// requested zones win; otherwise HA masters are spread across the first
+// masterCount cluster zones if there are enough of them. An empty result
+// leaves master placement to kops.
+func kopsMasterZoneList(requested string, zones []string, masterCount int) ([]string, error) {
+	if masterCount > 1 && masterCount%2 == 0 {
+		log.Printf("Warning: --kops-master-count=%d is even, an odd count tolerates as many master failures with fewer masters", masterCount)
+	}
+	if requested != "" {
+		masterZones := strings.Split(requested, ",")
+		if len(masterZones) > masterCount {
+			return nil, fmt.Errorf("--kops-master-zones lists %d zones, but only %d masters were requested", len(masterZones), masterCount)
+		}
+		return masterZones, nil
+	}
+	if masterCount > 1 && len(zones) >= masterCount {
+		return zones[:masterCount], nil
+	}
+	return nil, nil
+}
+
+// gceRegion returns the region of a GCE zone, e.g. us-central1 for us-central1-a.
+func gceRegion(zone string) (string, error) {
+	lastDash := strings.LastIndex(zone, "-")
+	if lastDash == -1 {
+		return "", fmt.Errorf("unexpected format for GCE zone: %q", zone)
+	}
+	return zone[0:lastDash], nil
+}
+
+// parseKopsInstanceGroups parses --kops-instance-groups, placing instance
+// groups that do not list any zones in the cluster zones.
+func parseKopsInstanceGroups(s string, zones []string) (map[string]kopsInstanceGroup, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var instanceGroups map[string]kopsInstanceGroup
+	if err := json.Unmarshal([]byte(s), &instanceGroups); err != nil {
+		return nil, fmt.Errorf("--kops-instance-groups must be valid JSON, unmarshal error: %v, JSON: %q", err, s)
+	}
+	for name, ig := range instanceGroups {
+		if ig.MinSize < 0 || ig.MaxSize < ig.MinSize {
+			return nil, fmt.Errorf("--kops-instance-groups: instance group %q must have 0 <= MinSize <= MaxSize, got %d and %d", name, ig.MinSize, ig.MaxSize)
+		}
+		if len(ig.Zones) == 0 {
+			ig.Zones = zones
+			instanceGroups[name] = ig
+		}
+	}
+	return instanceGroups, nil
+}
+
+// manifest returns a kops InstanceGroup manifest for the instance group
+// with the given name in the given cluster of the cloud provider.
+func (ig kopsInstanceGroup) manifest(name, cluster, provider string) ([]byte, error) {
+	spec := map[string]interface{}{
+		"role":    "Node",
+		"minSize": ig.MinSize,
+		"maxSize": ig.MaxSize,
+		"subnets": ig.Zones,
+	}
+	if provider == "gce" {
+		// GCE subnets are regional and named after the region.
+		if len(ig.Zones) == 0 {
+			return nil, errors.New("instance groups on gce need at least one zone")
+		}
+		region, err := gceRegion(ig.Zones[0])
+		if err != nil {
+			return nil, err
+		}
+		spec["zones"] = ig.Zones
+		spec["subnets"] = []string{region}
+	}
+	if ig.MachineType != "" {
+		spec["machineType"] = ig.MachineType
+	}
+	if ig.Image != "" {
+		spec["image"] = ig.Image
+	}
+	if len(ig.NodeLabels) > 0 {
+		spec["nodeLabels"] = ig.NodeLabels
+	}
+	return json.Marshal(map[string]interface{}{
+		"apiVersion": "kops.k8s.io/v1alpha2",
+		"kind":       "InstanceGroup",
+		"metadata": map[string]interface{}{
+			"name":   name,
+			"labels": map[string]string{"kops.k8s.io/cluster": cluster},
+		},
+		"spec": spec,
+	})
+}
+
+// createInstanceGroups creates the additional instance groups and applies
+// them to the cluster.
+func (k kops) createInstanceGroups() error {
+	if len(k.instanceGroups) == 0 {
+		return nil
+	}
+	for name, ig := range k.instanceGroups {
+		if err := k.createInstanceGroup(name, ig); err != nil {
+			return err
+		}
+	}
+	if err := control.FinishRunning(exec.Command(k.path, "update", "cluster", k.cluster, "--yes")); err != nil {
+		return fmt.Errorf("kops update cluster failed: %w", err)
+	}
+	return nil
+}
+
+// createInstanceGroup creates a single additional instance group from a
+// temporary manifest file.
+func (k kops) createInstanceGroup(name string, ig kopsInstanceGroup) error {
+	manifest, err := ig.manifest(name, k.cluster, k.provider)
+	if err != nil {
+		return fmt.Errorf("failed to generate manifest for instance group %q: %w", name, err)
+	}
+	f, err := ioutil.TempFile("", "kops-ig-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(manifest); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := control.FinishRunning(exec.Command(k.path, "create", "-f", f.Name())); err != nil {
+		return fmt.Errorf("kops create instance group %q failed: %w", name, err)
+	}
+	return nil
+}
+
+// expectedNodes is the number of nodes the cluster should have once it is
+// fully up, masters included.
+func (k kops) expectedNodes() int {
+	count := k.nodes + k.masterCount
+	for _, ig := range k.instanceGroups {
+		count += ig.MinSize
+	}
+	return count
+}
+
 func (k kops) isGoogleCloud() bool {
 	return k.provider == "gce"
 }
@@ -409,6 +592,9 @@ func (k kops) Up() error {
 		"--master-count", strconv.Itoa(k.masterCount),
 		"--zones", strings.Join(k.zones, ","),
 	}
+	if len(k.masterZones) > 0 {
+		createArgs = append(createArgs, "--master-zones", strings.Join(k.masterZones, ","))
+	}
 
 	var featureFlags []string
 	if k.featureFlags != "" {
@@ -483,8 +669,12 @@ func (k kops) Up() error {
 		return fmt.Errorf("kops create cluster failed: %w", err)
 	}
 
+	if err := k.createInstanceGroups(); err != nil {
+		return err
+	}
+
 	// TODO: Once this gets support for N checks in a row, it can replace the above node readiness check
-	if err := control.FinishRunning(exec.Command(k.path, "validate", "cluster", k.cluster, "--wait", "15m")); err != nil {
+	if err := control.FinishRunning(exec.Command(k.path, "validate", "cluster", k.cluster, "--wait", k.validateWait.String())); err != nil {
 		return fmt.Errorf("kops validate cluster failed: %w", err)
 	}
 
@@ -495,7 +685,7 @@ func (k kops) Up() error {
 	requiredConsecutiveSuccesses := 10
 
 	// Wait for nodes to become ready
-	if err := waitForReadyNodes(k.nodes+1, *kopsUpTimeout, requiredConsecutiveSuccesses); err != nil {
+	if err := waitForReadyNodes(k.expectedNodes(), *kopsUpTimeout, requiredConsecutiveSuccesses); err != nil {
 		return fmt.Errorf("kops nodes not ready: %w", err)
 	}
 
@@ -575,6 +765,9 @@ func (k kops) DumpClusterLogs(localPath, gcsPath string) error {
 
 	// Capture sysctl settings
 	logDumper.DumpSysctls = true
+	if k.provider == "gce" {
+		logDumper.SerialConsole = k.gceSerialConsole
+	}
 
 	ctx, cancel := context.WithCancel(context.TODO())
 	defer cancel()
@@ -596,6 +789,24 @@ func (k kops) DumpClusterLogs(localPath, gcsPath string) error {
 	}
 }
 
+// gceSerialConsole writes the serial port output of the GCE instance to w
+func (k *kops) gceSerialConsole(ctx context.Context, instance string, w io.Writer) error {
+	var err error
+	// The zone of the instance is unknown, so try the zones of the cluster.
+	for _, zone := range k.zones {
+		cmd := exec.CommandContext(ctx, "gcloud", "compute", "instances", "get-serial-port-output", instance,
+			"--project", k.gcpProject, "--zone", zone)
+		cmd.Stdout = w
+		if err = control.FinishRunning(cmd); err == nil {
+			return nil
+		}
+	}
+	if err == nil {
+		err = errors.New("cluster has no zones")
+	}
+	return err
+}
+
 // dumpAllNodes connects to every node and dumps the logs
 func (k *kops) dumpAllNodes(ctx context.Context, d *logDumper) error {
 	// Make sure kubeconfig is set, in particular before calling DumpAllNodes, which calls kubectlGetNodes
@@ -683,12 +894,11 @@ func (k kops) BuildTester(o *e2e.BuildTesterOptions) (e2e.Tester, error) {
 			zone := k.zones[0]
 			t.GCEZone = zone
 
-			// us-central1-a => us-central1
-			lastDash := strings.LastIndex(zone, "-")
-			if lastDash == -1 {
-				return nil, fmt.Errorf("unexpected format for GCE zone: %q", zone)
+			region, err := gceRegion(zone)
+			if err != nil {
+				return nil, err
 			}
-			t.GCERegion = zone[0:lastDash]
+			t.GCERegion = region
 		}
 	} else if k.provider == "aws" {
 		if len(k.zones) > 0 {
@@ -798,6 +1008,8 @@ func (k kops) Publish() error {
 
 func (k kops) KubectlCommand() (*exec.Cmd, error) { return nil, nil }
 
+func (k kops) kubeconfigPath() string { return k.kubecfg }
+
 // getRandomAWSZones looks up all regions, and the availability zones for those regions.  A random
 // region is then chosen and the AZ's for that region is returned. At least masterCount zones will be
 // returned, all in the same region.
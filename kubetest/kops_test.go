@@ -0,0 +1,210 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestKopsMasterZoneList(t *testing.T) {
+	zones := []string{"us-east-1a", "us-east-1b", "us-east-1c", "us-east-1d"}
+	cases := []struct {
+		name        string
+		requested   string
+		zones       []string
+		masterCount int
+		expected    []string
+		expectErr   bool
+	}{
+		{
+			name:        "single master is left to kops",
+			zones:       zones,
+			masterCount: 1,
+		},
+		{
+			name:        "HA masters are spread across the first zones",
+			zones:       zones,
+			masterCount: 3,
+			expected:    []string{"us-east-1a", "us-east-1b", "us-east-1c"},
+		},
+		{
+			name:        "HA masters with too few zones are left to kops",
+			zones:       zones[:1],
+			masterCount: 3,
+		},
+		{
+			name:        "requested zones win",
+			requested:   "us-east-1b,us-east-1d,us-east-1c",
+			zones:       zones,
+			masterCount: 3,
+			expected:    []string{"us-east-1b", "us-east-1d", "us-east-1c"},
+		},
+		{
+			name:        "more requested zones than masters",
+			requested:   "us-east-1b,us-east-1d",
+			zones:       zones,
+			masterCount: 1,
+			expectErr:   true,
+		},
+		{
+			name:        "even master counts are still spread",
+			zones:       zones,
+			masterCount: 2,
+			expected:    []string{"us-east-1a", "us-east-1b"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := kopsMasterZoneList(tc.requested, tc.zones, tc.masterCount)
+			if err != nil != tc.expectErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectErr, err)
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("Not equal: %v %v", actual, tc.expected)
+			}
+		})
+	}
+}
+
+func TestKopsInstanceGroupManifest(t *testing.T) {
+	cases := []struct {
+		name     string
+		provider string
+		zones    []string
+		spec     map[string]interface{}
+		err      bool
+	}{
+		{
+			name:     "aws subnets are named after the zones",
+			provider: "aws",
+			zones:    []string{"us-east-1a"},
+			spec: map[string]interface{}{
+				"subnets": []interface{}{"us-east-1a"},
+			},
+		},
+		{
+			name:     "gce subnets are named after the region",
+			provider: "gce",
+			zones:    []string{"us-central1-a", "us-central1-b"},
+			spec: map[string]interface{}{
+				"zones":   []interface{}{"us-central1-a", "us-central1-b"},
+				"subnets": []interface{}{"us-central1"},
+			},
+		},
+		{
+			name:     "gce needs a zone",
+			provider: "gce",
+			err:      true,
+		},
+		{
+			name:     "gce zone without a region",
+			provider: "gce",
+			zones:    []string{"central"},
+			err:      true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ig := kopsInstanceGroup{
+				MachineType: "p2.xlarge",
+				MinSize:     1,
+				MaxSize:     2,
+				Zones:       tc.zones,
+				NodeLabels:  map[string]string{"accelerator": "gpu"},
+			}
+			raw, err := ig.manifest("gpu", "e2e.k8s.local", tc.provider)
+			if err != nil != tc.err {
+				t.Fatalf("expected error: %t, got: %v", tc.err, err)
+			}
+			if tc.err {
+				return
+			}
+			var actual map[string]interface{}
+			if err := json.Unmarshal(raw, &actual); err != nil {
+				t.Fatalf("manifest is not valid JSON: %v", err)
+			}
+			spec := map[string]interface{}{
+				"role":        "Node",
+				"machineType": "p2.xlarge",
+				"minSize":     float64(1),
+				"maxSize":     float64(2),
+				"nodeLabels":  map[string]interface{}{"accelerator": "gpu"},
+			}
+			for k, v := range tc.spec {
+				spec[k] = v
+			}
+			expected := map[string]interface{}{
+				"apiVersion": "kops.k8s.io/v1alpha2",
+				"kind":       "InstanceGroup",
+				"metadata": map[string]interface{}{
+					"name":   "gpu",
+					"labels": map[string]interface{}{"kops.k8s.io/cluster": "e2e.k8s.local"},
+				},
+				"spec": spec,
+			}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("Not equal: %v %v", actual, expected)
+			}
+		})
+	}
+}
+
+func TestParseKopsInstanceGroups(t *testing.T) {
+	zones := []string{"us-east-1a", "us-east-1b"}
+	cases := []struct {
+		name     string
+		value    string
+		expected map[string]kopsInstanceGroup
+		err      bool
+	}{
+		{
+			name: "unset",
+		},
+		{
+			name:  "zones default to the cluster zones",
+			value: `{"gpu":{"MinSize":1,"MaxSize":1},"arm":{"MinSize":1,"MaxSize":2,"Zones":["us-east-1c"]}}`,
+			expected: map[string]kopsInstanceGroup{
+				"gpu": {MinSize: 1, MaxSize: 1, Zones: zones},
+				"arm": {MinSize: 1, MaxSize: 2, Zones: []string{"us-east-1c"}},
+			},
+		},
+		{
+			name:  "invalid sizes",
+			value: `{"gpu":{"MinSize":2,"MaxSize":1}}`,
+			err:   true,
+		},
+		{
+			name:  "invalid JSON",
+			value: `{"gpu":`,
+			err:   true,
+		},
+	}
+	for _, tc := range cases {
+		actual, err := parseKopsInstanceGroups(tc.value, zones)
+		switch {
+		case tc.err && err == nil:
+			t.Errorf("%s: expected an error, got %v", tc.name, actual)
+		case !tc.err && err != nil:
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		case !reflect.DeepEqual(actual, tc.expected):
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, actual)
+		}
+	}
+}
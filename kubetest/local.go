@@ -40,18 +40,18 @@ type localCluster struct {
 
 var _ deployer = localCluster{}
 
-func newLocalCluster() *localCluster {
+func newLocalCluster() (*localCluster, error) {
 	tempDir, err := ioutil.TempDir("", "kubetest-local")
 	if err != nil {
-		log.Fatal("unable to create temp directory")
+		return nil, fmt.Errorf("unable to create temp directory: %w", err)
 	}
 	err = os.Chmod(tempDir, 0755)
 	if err != nil {
-		log.Fatal("unable to change temp directory permissions")
+		return nil, fmt.Errorf("unable to change temp directory permissions: %w", err)
 	}
 	return &localCluster{
 		tempDir: tempDir,
-	}
+	}, nil
 }
 
 func (n localCluster) getScript(scriptPath string) (string, error) {
@@ -48,7 +48,7 @@ const defaultGinkgoParallel = 25
 
 var (
 	artifacts = filepath.Join(os.Getenv("WORKSPACE"), "_artifacts")
-	boskos, _ = client.NewClient(os.Getenv("JOB_NAME"), "http://boskos.test-pods.svc.cluster.local.", "", "")
+	boskos    *client.Client
 	control   = process.NewControl(timeout, interrupt, terminate, verbose)
 	gitTag    = ""                              // initializing default zero value. ldflags will populate this during build time.
 	interrupt = time.NewTimer(time.Duration(0)) // interrupt testing at this time.
@@ -58,21 +58,34 @@ var (
 )
 
 type options struct {
+	bazelArgs            string
+	bazelRemoteCache     string
+	bazelTest            string
 	build                buildStrategy
+	buildTimeout         time.Duration
+	boskosHeartbeat      time.Duration
+	boskosURL            string
 	boskosWaitDuration   time.Duration
 	charts               bool
 	checkLeaks           bool
+	checkpointFile       string
 	checkSkew            bool
 	cluster              string
 	clusterIPRange       string
+	conformance          bool
 	deployment           string
 	down                 bool
+	downgradeArgs        string
+	downTimeout          time.Duration
 	dump                 string
 	dumpPreTestLogs      string
+	dumpTimeout          time.Duration
 	extract              extractStrategies
+	extractCache         string
 	extractCIBucket      string
 	extractReleaseBucket string
 	extractSource        bool
+	extractVerify        bool
 	flushMemAfterBuild   bool
 	focusRegex           string
 	gcpCloudSdk          string
@@ -94,6 +107,8 @@ type options struct {
 	gcpSSHProxyInstanceName string
 	gcpRegion               string
 	gcpZone                 string
+	gcsUploadBucket         string
+	ginkgoFlakeAttempts     int
 	ginkgoParallel          ginkgoParallelValue
 	kubecfg                 string
 	kubemark                bool
@@ -101,21 +116,28 @@ type options struct {
 	kubemarkNodes           string // TODO(fejta): switch to int after migration
 	logexporterGCSPath      string
 	metadataSources         string
+	multiCluster            string
 	noAllowDup              bool
 	nodeArgs                string
 	nodeTestArgs            string
 	nodeTests               bool
 	outputDir               string
 	preTestCmd              string
+	preUpgradeTestArgs      string
+	postDowngradeTestArgs   string
 	postTestCmd             string
+	postUpgradeTestArgs     string
 	provider                string
 	publish                 string
+	resume                  bool
 	runtimeConfig           string
 	save                    string
 	skew                    bool
 	skipRegex               string
 	soak                    bool
 	soakDuration            time.Duration
+	soakTestDuration        time.Duration
+	soakUpload              string
 	sshUser                 string
 	stage                   stageStrategy
 	storageTestDriverPath   string
@@ -124,29 +146,51 @@ type options struct {
 	testCmd                 string
 	testCmdName             string
 	testCmdArgs             []string
+	testTimeout             time.Duration
 	up                      bool
+	upRetries               int
+	upRetryBackoff          time.Duration
+	upRetryableErrors       string
+	upRetryableRe           *regexp.Regexp
+	upTimeout               time.Duration
 	upgradeArgs             string
 	version                 bool
 }
 
+// defaultUpRetryableErrors matches the transient cloud errors worth retrying --up for.
+const defaultUpRetryableErrors = `(?i)quota|rate ?limit|resource_?exhausted|try again|backend ?error|internal error|temporarily unavailable`
+
 func defineFlags() *options {
 	o := options{}
+	flag.StringVar(&o.bazelArgs, "bazel-args", "", "Extra flags for the bazel build and test commands kubetest runs, e.g. --config=ci")
+	flag.StringVar(&o.bazelRemoteCache, "bazel-remote-cache", "", "If set, bazel builds and tests use this remote cache, e.g. https://storage.googleapis.com/bucket/cache")
+	flag.StringVar(&o.bazelTest, "bazel-test", "", "If set, bazel test these space separated targets in the kubernetes checkout and collect their results into --dump")
 	flag.Var(&o.build, "build", "Rebuild k8s binaries, optionally forcing (release|quick|bazel) strategy")
+	flag.DurationVar(&o.buildTimeout, "build-timeout", 0, "If positive, fail the build phase and kill its commands after this duration (s/m/h)")
+	flag.DurationVar(&o.boskosHeartbeat, "boskos-heartbeat-interval", 5*time.Minute, "How often to tell Boskos that a leased project is still in use")
+	flag.StringVar(&o.boskosURL, "boskos-url", "http://boskos.test-pods.svc.cluster.local.", "Boskos server to lease GCP projects from when --gcp-project is unset")
 	flag.DurationVar(&o.boskosWaitDuration, "boskos-wait-duration", 5*time.Minute, "Defines how long it waits until quit getting Boskos resoure, default 5 minutes")
 	flag.BoolVar(&o.charts, "charts", false, "If true, run charts tests")
 	flag.BoolVar(&o.checkSkew, "check-version-skew", true, "Verify client and server versions match")
 	flag.BoolVar(&o.checkLeaks, "check-leaked-resources", false, "Ensure project ends with the same resources")
+	flag.StringVar(&o.checkpointFile, "checkpoint-file", "", "If set, record the completed up, test and dump phases in this file so that --resume can skip them")
 	flag.StringVar(&o.cluster, "cluster", "", "Cluster name. Must be set for --deployment=gke (TODO: other deployments).")
+	flag.BoolVar(&o.conformance, "conformance", false, "If true, --test runs only the conformance suite, serially and against the skeleton provider, and lays out the results for a conformance submission in the conformance directory of --dump")
 	flag.StringVar(&o.clusterIPRange, "cluster-ip-range", "", "Specifies CLUSTER_IP_RANGE value during --up and --test (only relevant for --deployment=bash). Auto-calculated if empty.")
 	flag.StringVar(&o.deployment, "deployment", "bash", "Choices: none/bash/conformance/gke/kind/kops/node/local")
 	flag.BoolVar(&o.down, "down", false, "If true, tear down the cluster before exiting.")
+	flag.DurationVar(&o.downTimeout, "down-timeout", 0, "If positive, fail the down phase and kill its commands after this duration (s/m/h)")
 	flag.StringVar(&o.dump, "dump", "", "If set, dump bring-up and cluster logs to this location on test or cluster-up failure")
 	flag.StringVar(&o.dumpPreTestLogs, "dump-pre-test-logs", "", "If set, dump cluster logs to this location before running tests")
+	flag.DurationVar(&o.dumpTimeout, "dump-timeout", 0, "If positive, fail the log dump phase and kill its commands after this duration (s/m/h)")
 	flag.Var(&o.extract, "extract", "Extract k8s binaries from the specified release location")
+	flag.StringVar(&o.extractCache, "extract-cache", "", "If set, keep extracted releases in this directory and reuse them when the same version is extracted again")
 	flag.StringVar(&o.extractCIBucket, "extract-ci-bucket", "k8s-release-dev", "Extract k8s CI binaries from the specified GCS bucket")
 	flag.StringVar(&o.extractReleaseBucket, "extract-release-bucket", "kubernetes-release", "Extract k8s release binaries from the specified GCS bucket")
 	flag.BoolVar(&o.extractSource, "extract-source", false, "Extract k8s src together with other tarballs")
+	flag.BoolVar(&o.extractVerify, "extract-verify", false, "Verify downloaded release tarballs against their published sha512 checksums")
 	flag.BoolVar(&o.flushMemAfterBuild, "flush-mem-after-build", false, "If true, try to flush container memory after building")
+	flag.IntVar(&o.ginkgoFlakeAttempts, "ginkgo-flake-attempts", 0, "If positive, make Ginkgo run a failing test up to this many times before reporting it failed")
 	flag.Var(&o.ginkgoParallel, "ginkgo-parallel", fmt.Sprintf("Run Ginkgo tests in parallel, default %d runners. Use --ginkgo-parallel=N to specify an exact count.", defaultGinkgoParallel))
 	flag.StringVar(&o.gcpCloudSdk, "gcp-cloud-sdk", "", "Install/upgrade google-cloud-sdk to the gs:// path if set")
 	flag.StringVar(&o.gcpProject, "gcp-project", "", "For use with gcloud commands")
@@ -154,6 +198,7 @@ func defineFlags() *options {
 	flag.StringVar(&o.gcpServiceAccount, "gcp-service-account", "", "Service account to activate before using gcloud")
 	flag.StringVar(&o.gcpZone, "gcp-zone", "", "For use with gcloud commands")
 	flag.StringVar(&o.gcpRegion, "gcp-region", "", "For use with gcloud commands")
+	flag.StringVar(&o.gcsUploadBucket, "gcs-upload-bucket", "", "If set, upload started.json, finished.json, the build log and --dump to this run's canonical path in this gs:// bucket, for jobs prow does not decorate")
 	flag.StringVar(&o.gcpNetwork, "gcp-network", "", "Cluster network. Must be set for --deployment=gke (TODO: other deployments).")
 	flag.StringVar(&o.gcpMasterImage, "gcp-master-image", "", "Master image type (cos|debian on GCE, n/a on GKE)")
 	flag.StringVar(&o.gcpMasterSize, "gcp-master-size", "", "(--provider=gce only) Size of master to create (e.g n1-standard-1). Auto-calculated if left empty.")
@@ -164,12 +209,13 @@ func defineFlags() *options {
 	flag.StringVar(&o.gcpNodeSize, "gcp-node-size", "", "(--provider=gce only) Size of nodes to create (e.g n1-standard-1).")
 	flag.StringVar(&o.gcpSSHProxyInstanceName, "gcp-ssh-proxy-instance-name", "", "(--provider=gce|gke only) If set, will result in proxing the ssh connections via the provided instance name while running tests")
 	flag.StringVar(&o.kubecfg, "kubeconfig", "", "The location of a kubeconfig file.")
-	flag.StringVar(&o.focusRegex, "ginkgo-focus", "", "The ginkgo regex to focus. Currently only respected for (dind).")
-	flag.StringVar(&o.skipRegex, "ginkgo-skip", "", "The ginkgo regex to skip. Currently only respected for (dind).")
+	flag.StringVar(&o.focusRegex, "ginkgo-focus", "", "The ginkgo regex to focus. Overrides --ginkgo.focus in --test_args.")
+	flag.StringVar(&o.skipRegex, "ginkgo-skip", "", "The ginkgo regex to skip. Overrides --ginkgo.skip in --test_args.")
 	flag.BoolVar(&o.kubemark, "kubemark", false, "If true, run kubemark tests.")
 	flag.StringVar(&o.kubemarkMasterSize, "kubemark-master-size", "", "Kubemark master size (only relevant if --kubemark=true). Auto-calculated based on '--kubemark-nodes' if left empty.")
 	flag.StringVar(&o.kubemarkNodes, "kubemark-nodes", "5", "Number of kubemark nodes to start (only relevant if --kubemark=true).")
 	flag.StringVar(&o.logexporterGCSPath, "logexporter-gcs-path", "", "Path to the GCS artifacts directory to dump logs from nodes. Logexporter gets enabled if this is non-empty")
+	flag.StringVar(&o.multiCluster, "multi-cluster", "", "If set, bring up, test and tear down a cluster for each of these comma separated name[@zone] clusters instead of --cluster (gke and kops only, zones for gke only)")
 	flag.StringVar(&o.metadataSources, "metadata-sources", "images.json", "Comma-separated list of files inside ./artifacts to merge into metadata.json")
 	flag.StringVar(&o.nodeArgs, "node-args", "", "Args for node e2e tests.")
 	flag.StringVar(&o.nodeTestArgs, "node-test-args", "", "Test args specifically for node e2e tests.")
@@ -179,22 +225,35 @@ func defineFlags() *options {
 	flag.StringVar(&o.postTestCmd, "post-test-cmd", "", "If set, run the provided command after running all the tests.")
 	flag.StringVar(&o.provider, "provider", "", "Kubernetes provider such as gce, gke, aws, etc")
 	flag.StringVar(&o.publish, "publish", "", "Publish version to the specified gs:// path on success")
+	flag.BoolVar(&o.resume, "resume", false, "If true, skip the phases --checkpoint-file records as completed and reuse the existing cluster, e.g. to retry a failed test phase")
 	flag.StringVar(&o.runtimeConfig, "runtime-config", "", "If set, API versions can be turned on or off while bringing up the API server.")
 	flag.StringVar(&o.stage.dockerRegistry, "registry", "", "Push images to the specified docker registry (e.g. gcr.io/a-test-project)")
 	flag.StringVar(&o.save, "save", "", "Save credentials to gs:// path on --up if set (or load from there if not --up)")
 	flag.BoolVar(&o.skew, "skew", false, "If true, run tests in another version at ../kubernetes/kubernetes_skew")
 	flag.BoolVar(&o.soak, "soak", false, "If true, job runs in soak mode")
 	flag.DurationVar(&o.soakDuration, "soak-duration", 7*24*time.Hour, "Maximum age of a soak cluster before it gets recycled")
+	flag.DurationVar(&o.soakTestDuration, "soak-test-duration", 0, "If positive with --soak, repeatedly run --test against the same cluster for this duration (s/m/h)")
+	flag.StringVar(&o.soakUpload, "soak-upload", "", "If set with --soak-test-duration, upload the results of each soak iteration below this gs:// path as soon as it finishes")
 	flag.Var(&o.stage, "stage", "Upload binaries to gs://bucket/devel/job-suffix if set")
+	flag.StringVar(&o.stage.marker, "stage-marker", "", "If set with --stage, write the staged version to gs://bucket/ci/suffix/MARKER.txt so that other jobs can --extract it")
 	flag.StringVar(&o.stage.versionSuffix, "stage-suffix", "", "Append suffix to staged version when set")
 	flag.StringVar(&o.storageTestDriverPath, "storage-testdriver-repo-path", "", "Relative path for external e2e test driver config in the csi driver repo")
 	flag.BoolVar(&o.test, "test", false, "Run Ginkgo tests.")
 	flag.StringVar(&o.testArgs, "test_args", "", "Space-separated list of arguments to pass to Ginkgo test runner.")
 	flag.StringVar(&o.testCmd, "test-cmd", "", "command to run against the cluster instead of Ginkgo e2e tests")
 	flag.StringVar(&o.testCmdName, "test-cmd-name", "", "name to log the test command as in xml results")
+	flag.DurationVar(&o.testTimeout, "test-timeout", 0, "If positive, fail the test phase and kill its commands after this duration (s/m/h)")
 	flag.DurationVar(&timeout, "timeout", time.Duration(0), "Terminate testing after the timeout duration (s/m/h)")
 	flag.BoolVar(&o.up, "up", false, "If true, start the e2e cluster. If cluster is already up, recreate it.")
+	flag.IntVar(&o.upRetries, "up-retries", 0, "Retry a failed --up this many times when its error matches --up-retryable-errors, tearing down the partial cluster in between")
+	flag.DurationVar(&o.upRetryBackoff, "up-retry-backoff", time.Minute, "How long to wait before the first --up retry, doubled for each further retry")
+	flag.StringVar(&o.upRetryableErrors, "up-retryable-errors", defaultUpRetryableErrors, "Regexp matching the --up errors and output worth retrying, such as quota or rate limit errors")
+	flag.DurationVar(&o.upTimeout, "up-timeout", 0, "If positive, fail the up phase and kill its commands after this duration (s/m/h)")
 	flag.StringVar(&o.upgradeArgs, "upgrade_args", "", "If set, run upgrade tests before other tests")
+	flag.StringVar(&o.preUpgradeTestArgs, "pre-upgrade-test-args", "", "If set, run tests with these args against the cluster before --upgrade_args")
+	flag.StringVar(&o.postUpgradeTestArgs, "post-upgrade-test-args", "", "If set, run tests with these args from the skew directory after --upgrade_args")
+	flag.StringVar(&o.downgradeArgs, "downgrade-args", "", "If set, run downgrade tests with these args after the post-upgrade tests")
+	flag.StringVar(&o.postDowngradeTestArgs, "post-downgrade-test-args", "", "If set, run tests with these args against the cluster after --downgrade-args")
 	flag.BoolVar(&o.version, "version", false, "Command to print version")
 
 	// The "-v" flag was also used by glog, which is used by k8s.io/client-go. Duplicate flags cause panics.
@@ -246,6 +305,9 @@ type publisher interface {
 }
 
 func getDeployer(o *options) (deployer, error) {
+	if o.multiCluster != "" {
+		return newMultiClusterDeployer(o)
+	}
 	switch o.deployment {
 	case "bash":
 		return newBash(&o.clusterIPRange, o.gcpProject, o.gcpZone, o.gcpSSHProxyInstanceName, o.provider), nil
@@ -262,7 +324,7 @@ func getDeployer(o *options) (deployer, error) {
 	case "none":
 		return noneDeploy{}, nil
 	case "local":
-		return newLocalCluster(), nil
+		return newLocalCluster()
 	case "aksengine":
 		return newAKSEngine()
 	case "aks":
@@ -276,6 +338,65 @@ func validateFlags(o *options) error {
 	if !o.extract.Enabled() && o.extractSource {
 		return errors.New("--extract-source flag cannot be passed without --extract")
 	}
+	if !o.extract.Enabled() && (o.extractCache != "" || o.extractVerify) {
+		return errors.New("--extract-cache and --extract-verify cannot be passed without --extract")
+	}
+	if !o.stage.Enabled() && o.stage.marker != "" {
+		return errors.New("--stage-marker cannot be passed without --stage")
+	}
+	if o.resume && o.checkpointFile == "" {
+		return errors.New("--resume flag cannot be passed without --checkpoint-file")
+	}
+	if o.soakTestDuration > 0 && !o.soak {
+		return errors.New("--soak-test-duration flag cannot be passed without --soak")
+	}
+	if o.soakTestDuration > 0 && timeout > 0 && o.soakTestDuration >= timeout {
+		return fmt.Errorf("--soak-test-duration (%s) must be shorter than --timeout (%s)", o.soakTestDuration, timeout)
+	}
+	if o.soakUpload != "" && o.soakTestDuration <= 0 {
+		return errors.New("--soak-upload flag cannot be passed without --soak-test-duration")
+	}
+	if o.upRetries < 0 {
+		return errors.New("--up-retries must not be negative")
+	}
+	if o.upRetries > 0 {
+		re, err := regexp.Compile(o.upRetryableErrors)
+		if err != nil {
+			return fmt.Errorf("--up-retryable-errors is not a valid regexp: %w", err)
+		}
+		o.upRetryableRe = re
+	}
+	if o.ginkgoFlakeAttempts < 0 {
+		return errors.New("--ginkgo-flake-attempts must not be negative")
+	}
+	if o.conformance {
+		if !o.test {
+			return errors.New("--conformance requires --test")
+		}
+		if o.dump == "" {
+			return errors.New("--conformance requires --dump to lay out the results in")
+		}
+		if o.focusRegex != "" || o.skipRegex != "" || o.ginkgoFlakeAttempts != 0 || o.ginkgoParallel.Get() > 1 {
+			return errors.New("--conformance runs a fixed suite and cannot be combined with --ginkgo-focus, --ginkgo-skip, --ginkgo-flake-attempts or --ginkgo-parallel")
+		}
+		if o.skew || o.nodeTests {
+			return errors.New("--conformance cannot be combined with --skew or --node-tests")
+		}
+	}
+	if o.boskosHeartbeat <= 0 {
+		return errors.New("--boskos-heartbeat-interval must be positive")
+	}
+	for name, d := range map[string]time.Duration{
+		"--build-timeout": o.buildTimeout,
+		"--up-timeout":    o.upTimeout,
+		"--test-timeout":  o.testTimeout,
+		"--dump-timeout":  o.dumpTimeout,
+		"--down-timeout":  o.downTimeout,
+	} {
+		if d < 0 {
+			return fmt.Errorf("%s must not be negative", name)
+		}
+	}
 	return nil
 }
 
@@ -304,6 +425,11 @@ func main() {
 
 	control = process.NewControl(timeout, interrupt, terminate, verbose)
 
+	var err error
+	if boskos, err = client.NewClient(os.Getenv("JOB_NAME"), o.boskosURL, "", ""); err != nil {
+		log.Fatalf("Failed to create Boskos client for %s: %v", o.boskosURL, err)
+	}
+
 	// do things when we know we are running in the kubetest image
 	if os.Getenv("KUBETEST_IN_DOCKER") == "true" {
 		o.flushMemAfterBuild = true
@@ -319,7 +445,20 @@ func main() {
 		o.dump = artifacts
 	}
 
-	err := complete(o)
+	var results *gcsResults
+	if o.gcsUploadBucket != "" {
+		if results, err = startGCSResults(o.gcsUploadBucket, o.dump); err != nil {
+			log.Fatalf("Failed to start uploading results to %s: %v", o.gcsUploadBucket, err)
+		}
+	}
+
+	err = complete(o)
+
+	if results != nil {
+		if uerr := results.finish(err == nil); uerr != nil {
+			log.Printf("Failed to upload results: %v", uerr)
+		}
+	}
 
 	if boskos.HasResource() {
 		if berr := boskos.ReleaseAll("dirty"); berr != nil {
@@ -332,7 +471,7 @@ func main() {
 	}
 }
 
-func complete(o *options) error {
+func complete(o *options) (cerr error) {
 	if !terminate.Stop() {
 		<-terminate.C // Drain the value if necessary.
 	}
@@ -347,7 +486,15 @@ func complete(o *options) error {
 
 	if o.dump != "" {
 		defer writeMetadata(o.dump, o.metadataSources)
-		defer control.WriteXML(&suite, o.dump, time.Now())
+		start := time.Now()
+		defer func() {
+			if err := control.WriteXML(&suite, o.dump, start); err != nil {
+				log.Printf("Failed to write junit_runner.xml: %v", err)
+				if cerr == nil {
+					cerr = err
+				}
+			}
+		}()
 	}
 	if o.logexporterGCSPath != "" {
 		o.testArgs += fmt.Sprintf(" --logexporter-gcs-path=%s", o.logexporterGCSPath)
@@ -433,15 +580,17 @@ func acquireKubernetes(o *options, d deployer) error {
 	if o.build.Enabled() {
 		var err error
 		// kind deployer manages build
+		build := func() error {
+			return o.build.Build(bazelFlags(o.bazelRemoteCache, o.bazelArgs))
+		}
 		if k, ok := d.(*kind.Deployer); ok {
-			err = control.XMLWrap(&suite, "Build", k.Build)
+			build = k.Build
 		} else if c, ok := d.(*aksEngineDeployer); ok { // Azure deployer
-			err = control.XMLWrap(&suite, "Build", func() error {
-				return c.Build(o.build)
-			})
-		} else {
-			err = control.XMLWrap(&suite, "Build", o.build.Build)
+			build = func() error {
+				return c.Build(o.build, bazelFlags(o.bazelRemoteCache, o.bazelArgs))
+			}
 		}
+		err = control.XMLWrapPhase(&suite, "build", "Build", o.buildTimeout, build)
 		if o.flushMemAfterBuild {
 			util.FlushMem()
 		}
@@ -478,6 +627,8 @@ func acquireKubernetes(o *options, d deployer) error {
 			}
 
 			// New deployment, extract new version
+			extractCache = releaseCache{dir: o.extractCache}
+			extractVerify = o.extractVerify
 			return o.extract.Extract(o.gcpProject, o.gcpZone, o.gcpRegion, o.extractCIBucket, o.extractReleaseBucket, o.extractSource)
 		})
 		if err != nil {
@@ -748,38 +899,11 @@ func prepareGcp(o *options) error {
 	if o.gcpProject == "" {
 		log.Print("--gcp-project is missing, trying to fetch a project from boskos.\n" +
 			"(for local runs please set --gcp-project to your dev project)")
-
-		var resType string
-		if o.gcpProjectType != "" {
-			resType = o.gcpProjectType
-		} else if o.provider == "gke" {
-			resType = "gke-project"
-		} else {
-			resType = "gce-project"
-		}
-
-		log.Printf("provider %v, will acquire project type %v from boskos", o.provider, resType)
-
-		// let's retry 5min to get next available resource
-		ctx, cancel := context.WithTimeout(context.Background(), o.boskosWaitDuration)
-		defer cancel()
-		p, err := boskos.AcquireWait(ctx, resType, "free", "busy")
+		project, err := acquireGCPProject(o)
 		if err != nil {
-			return fmt.Errorf("--provider=%s boskos failed to acquire project: %w", o.provider, err)
-		}
-
-		if p == nil {
-			return fmt.Errorf("boskos does not have a free %s at the moment", resType)
+			return err
 		}
-
-		go func(c *client.Client, proj string) {
-			for range time.Tick(time.Minute * 5) {
-				if err := c.UpdateOne(p.Name, "busy", nil); err != nil {
-					log.Printf("[Boskos] Update of %s failed with %v", p.Name, err)
-				}
-			}
-		}(boskos, p.Name)
-		o.gcpProject = p.Name
+		o.gcpProject = project
 	}
 
 	if err := os.Setenv("CLOUDSDK_CORE_PRINT_UNHANDLED_TRACEBACKS", "1"); err != nil {
@@ -876,6 +1000,50 @@ func prepareGcp(o *options) error {
 	return nil
 }
 
+// acquireGCPProject leases a project of the type matching --gcp-project-type
+// or the provider from boskos and keeps the lease alive until kubetest exits.
+// The lease is released as dirty in main so the janitor cleans up after us.
+func acquireGCPProject(o *options) (string, error) {
+	var resType string
+	if o.gcpProjectType != "" {
+		resType = o.gcpProjectType
+	} else if o.provider == "gke" {
+		resType = "gke-project"
+	} else {
+		resType = "gce-project"
+	}
+
+	log.Printf("provider %v, will acquire project type %v from boskos at %s", o.provider, resType, o.boskosURL)
+
+	// let's retry 5min to get next available resource
+	ctx, cancel := context.WithTimeout(context.Background(), o.boskosWaitDuration)
+	defer cancel()
+	p, err := boskos.AcquireWait(ctx, resType, "free", "busy")
+	if err != nil {
+		return "", fmt.Errorf("--provider=%s boskos failed to acquire project: %w", o.provider, err)
+	}
+
+	if p == nil {
+		return "", fmt.Errorf("boskos does not have a free %s at the moment", resType)
+	}
+	log.Printf("[Boskos] Acquired project %s", p.Name)
+
+	go heartbeatBoskos(boskos, p.Name, o.boskosHeartbeat)
+	return p.Name, nil
+}
+
+// heartbeatBoskos periodically marks the leased resource as busy so that the
+// boskos reaper does not reclaim it while we are still using it.
+func heartbeatBoskos(c *client.Client, name string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := c.UpdateOne(name, "busy", nil); err != nil {
+			log.Printf("[Boskos] Update of %s failed with %v", name, err)
+		}
+	}
+}
+
 func prepareAws(o *options) error {
 	// gcloud creds may have changed
 	if err := activateServiceAccount(o.gcpServiceAccount); err != nil {
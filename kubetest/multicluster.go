@@ -0,0 +1,259 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s.io/test-infra/kubetest/util"
+)
+
+// clusterSpec is a cluster of --multi-cluster.
+type clusterSpec struct {
+	name string
+	zone string // overrides --gcp-zone if set
+}
+
+// parseMultiCluster parses a comma separated list of name[@zone] clusters.
+func parseMultiCluster(s string) ([]clusterSpec, error) {
+	var specs []clusterSpec
+	seen := map[string]bool{}
+	for _, c := range strings.Split(s, ",") {
+		parts := strings.SplitN(strings.TrimSpace(c), "@", 2)
+		spec := clusterSpec{name: parts[0]}
+		if len(parts) == 2 {
+			spec.zone = parts[1]
+			if spec.zone == "" {
+				return nil, fmt.Errorf("cluster %q has an empty zone", c)
+			}
+		}
+		if spec.name == "" {
+			return nil, fmt.Errorf("cluster %q has an empty name", c)
+		}
+		if seen[spec.name] {
+			return nil, fmt.Errorf("cluster %q is listed more than once", spec.name)
+		}
+		seen[spec.name] = true
+		specs = append(specs, spec)
+	}
+	if len(specs) < 2 {
+		return nil, fmt.Errorf("--multi-cluster needs at least 2 clusters, got %q", s)
+	}
+	return specs, nil
+}
+
+// kubeconfigOwner is implemented by deployers that keep the credentials of
+// their cluster in a kubeconfig file of their own.
+type kubeconfigOwner interface {
+	kubeconfigPath() string
+}
+
+// multiClusterDeployer brings up and tears down several clusters of the same
+// deployment, handing the tests a kubeconfig with a context for each of them.
+type multiClusterDeployer struct {
+	names   []string
+	members []deployer
+	merged  string // merged kubeconfig
+}
+
+var _ deployer = &multiClusterDeployer{}
+
+// newMultiClusterDeployer creates a --deployment deployer for each cluster of
+// --multi-cluster, which is named after it and uses its zone.
+func newMultiClusterDeployer(o *options) (_ *multiClusterDeployer, err error) {
+	if o.deployment != "gke" && o.deployment != "kops" {
+		return nil, fmt.Errorf("--multi-cluster is not supported for --deployment=%s", o.deployment)
+	}
+	specs, err := parseMultiCluster(o.multiCluster)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := ioutil.TempFile("", "multicluster-kubecfg")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	defer func() {
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}()
+	if err := f.Chmod(0600); err != nil {
+		return nil, err
+	}
+	m := &multiClusterDeployer{merged: f.Name()}
+
+	for i, spec := range specs {
+		if spec.zone != "" && o.deployment != "gke" {
+			return nil, fmt.Errorf("cluster %s: zones are only supported for --deployment=gke", spec.name)
+		}
+		c := *o
+		c.multiCluster = ""
+		c.cluster = spec.name
+		if spec.zone != "" {
+			c.gcpZone = spec.zone
+		}
+		d, err := getDeployer(&c)
+		if err != nil {
+			return nil, fmt.Errorf("error creating deployer for cluster %s: %w", spec.name, err)
+		}
+		if _, ok := d.(kubeconfigOwner); !ok {
+			return nil, fmt.Errorf("--deployment=%s does not keep a kubeconfig per cluster", o.deployment)
+		}
+		if i == 0 {
+			// Deployers may default test flags, which are shared by all clusters.
+			o.testArgs, o.upgradeArgs = c.testArgs, c.upgradeArgs
+		}
+		m.names = append(m.names, spec.name)
+		m.members = append(m.members, d)
+	}
+	return m, nil
+}
+
+// each calls f for every cluster, pointing KUBECONFIG at the cluster's own
+// kubeconfig while it runs. It calls f for all clusters even if some fail.
+func (m *multiClusterDeployer) each(f func(name string, d deployer) error) error {
+	var errs []error
+	for i, d := range m.members {
+		if err := os.Setenv("KUBECONFIG", d.(kubeconfigOwner).kubeconfigPath()); err != nil {
+			errs = append(errs, fmt.Errorf("cluster %s: %w", m.names[i], err))
+			continue
+		}
+		if err := f(m.names[i], d); err != nil {
+			errs = append(errs, fmt.Errorf("cluster %s: %w", m.names[i], err))
+		}
+	}
+	if fi, err := os.Stat(m.merged); err == nil && fi.Size() == 0 {
+		// Up did not get as far as merging, e.g. because a cluster failed to come up.
+		if err := m.merge(); err != nil {
+			log.Printf("Failed to merge the kubeconfigs: %v", err)
+		}
+	}
+	if err := os.Setenv("KUBECONFIG", m.merged); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("encountered %d errors: %v", len(errs), errs)
+	}
+	return nil
+}
+
+// Up brings up every cluster, stopping at the first one that fails, and
+// merges their kubeconfigs.
+func (m *multiClusterDeployer) Up() error {
+	for i, d := range m.members {
+		log.Printf("Bringing up cluster %s (%d/%d)", m.names[i], i+1, len(m.members))
+		if err := os.Setenv("KUBECONFIG", d.(kubeconfigOwner).kubeconfigPath()); err != nil {
+			return err
+		}
+		if err := d.Up(); err != nil {
+			return fmt.Errorf("cluster %s: %w", m.names[i], err)
+		}
+	}
+	if err := m.merge(); err != nil {
+		return err
+	}
+	return os.Setenv("KUBECONFIG", m.merged)
+}
+
+func (m *multiClusterDeployer) IsUp() error {
+	return m.each(func(_ string, d deployer) error { return d.IsUp() })
+}
+
+// DumpClusterLogs dumps the logs of each cluster into a directory named after it.
+func (m *multiClusterDeployer) DumpClusterLogs(localPath, gcsPath string) error {
+	return m.each(func(name string, d deployer) error {
+		path := filepath.Join(localPath, name)
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return err
+		}
+		clusterGCSPath := gcsPath
+		if gcsPath != "" {
+			clusterGCSPath = strings.TrimSuffix(gcsPath, "/") + "/" + name
+		}
+		return d.DumpClusterLogs(path, clusterGCSPath)
+	})
+}
+
+// TestSetup sets up every cluster and merges their kubeconfigs again, as
+// setting up may have refreshed their credentials.
+func (m *multiClusterDeployer) TestSetup() error {
+	if err := m.each(func(_ string, d deployer) error { return d.TestSetup() }); err != nil {
+		return err
+	}
+	return m.merge()
+}
+
+// Down tears down every cluster, even if tearing down some of them fails.
+func (m *multiClusterDeployer) Down() error {
+	return m.each(func(_ string, d deployer) error { return d.Down() })
+}
+
+// GetClusterCreated returns when the oldest cluster was created.
+func (m *multiClusterDeployer) GetClusterCreated(gcpProject string) (time.Time, error) {
+	var oldest time.Time
+	for i, d := range m.members {
+		created, err := d.GetClusterCreated(gcpProject)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("cluster %s: %w", m.names[i], err)
+		}
+		if oldest.IsZero() || created.Before(oldest) {
+			oldest = created
+		}
+	}
+	return oldest, nil
+}
+
+func (m *multiClusterDeployer) KubectlCommand() (*exec.Cmd, error) {
+	return m.members[0].KubectlCommand()
+}
+
+// merge merges the kubeconfigs of every cluster into m.merged.
+func (m *multiClusterDeployer) merge() error {
+	var kubeconfigs []string
+	for _, d := range m.members {
+		kubeconfigs = append(kubeconfigs, d.(kubeconfigOwner).kubeconfigPath())
+	}
+	return mergeKubeconfigs(kubeconfigs, m.merged)
+}
+
+// mergeKubeconfigs writes the flattened merge of the kubeconfigs to dest.
+var mergeKubeconfigs = func(kubeconfigs []string, dest string) error {
+	if len(kubeconfigs) == 0 {
+		return errors.New("no kubeconfigs to merge")
+	}
+	cmd := exec.Command("kubectl", "config", "view", "--flatten")
+	cmd.Env = append(os.Environ(), "KUBECONFIG="+strings.Join(kubeconfigs, string(os.PathListSeparator)))
+	b, err := control.Output(cmd)
+	if err != nil {
+		return fmt.Errorf("error merging kubeconfigs: %s", util.ExecError(err))
+	}
+	if err := ioutil.WriteFile(dest, b, 0600); err != nil {
+		return err
+	}
+	log.Printf("Merged the kubeconfigs of %d clusters into %s", len(kubeconfigs), dest)
+	return nil
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMultiCluster(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected []clusterSpec
+		err      bool
+	}{
+		{
+			value:    "a,b",
+			expected: []clusterSpec{{name: "a"}, {name: "b"}},
+		},
+		{
+			value:    "a@us-central1-a, b@europe-west1-b",
+			expected: []clusterSpec{{name: "a", zone: "us-central1-a"}, {name: "b", zone: "europe-west1-b"}},
+		},
+		{value: "a", err: true},
+		{value: "a,a", err: true},
+		{value: "a,", err: true},
+		{value: "a@,b", err: true},
+	}
+	for _, tc := range cases {
+		actual, err := parseMultiCluster(tc.value)
+		switch {
+		case tc.err && err == nil:
+			t.Errorf("%q: expected an error, got %v", tc.value, actual)
+		case !tc.err && err != nil:
+			t.Errorf("%q: unexpected error: %v", tc.value, err)
+		case !reflect.DeepEqual(actual, tc.expected):
+			t.Errorf("%q: expected %v, got %v", tc.value, tc.expected, actual)
+		}
+	}
+}
+
+// fakeClusterDeployer records the KUBECONFIG each of its calls saw.
+type fakeClusterDeployer struct {
+	kubeconfig string
+	created    time.Time
+	downErr    error
+	seen       []string
+}
+
+func (f *fakeClusterDeployer) record() { f.seen = append(f.seen, os.Getenv("KUBECONFIG")) }
+
+func (f *fakeClusterDeployer) Up() error                                   { f.record(); return nil }
+func (f *fakeClusterDeployer) IsUp() error                                 { f.record(); return nil }
+func (f *fakeClusterDeployer) DumpClusterLogs(_, _ string) error           { f.record(); return nil }
+func (f *fakeClusterDeployer) TestSetup() error                            { f.record(); return nil }
+func (f *fakeClusterDeployer) Down() error                                 { f.record(); return f.downErr }
+func (f *fakeClusterDeployer) GetClusterCreated(string) (time.Time, error) { return f.created, nil }
+func (f *fakeClusterDeployer) KubectlCommand() (*exec.Cmd, error)          { return nil, nil }
+func (f *fakeClusterDeployer) kubeconfigPath() string                      { return f.kubeconfig }
+
+func TestMultiClusterDeployer(t *testing.T) {
+	pre := os.Getenv("KUBECONFIG")
+	defer os.Setenv("KUBECONFIG", pre)
+	merged, err := ioutil.TempFile("", "merged")
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged.Close()
+	defer os.Remove(merged.Name())
+	preMerge := mergeKubeconfigs
+	defer func() { mergeKubeconfigs = preMerge }()
+	var merges [][]string
+	mergeKubeconfigs = func(kubeconfigs []string, dest string) error {
+		merges = append(merges, kubeconfigs)
+		return ioutil.WriteFile(dest, []byte("merged"), 0600)
+	}
+
+	now := time.Now()
+	a := &fakeClusterDeployer{kubeconfig: "/a", created: now, downErr: errors.New("quota")}
+	b := &fakeClusterDeployer{kubeconfig: "/b", created: now.Add(-time.Hour)}
+	m := &multiClusterDeployer{
+		names:   []string{"a", "b"},
+		members: []deployer{a, b},
+		merged:  merged.Name(),
+	}
+
+	if err := m.Up(); err != nil {
+		t.Errorf("unexpected Up error: %v", err)
+	}
+	// The kubeconfigs are merged as soon as the clusters are up.
+	if expected := [][]string{{"/a", "/b"}}; !reflect.DeepEqual(merges, expected) {
+		t.Errorf("expected merges %v after Up, got %v", expected, merges)
+	}
+	err = m.Down()
+	if err == nil || !strings.Contains(err.Error(), "cluster a: quota") {
+		t.Errorf("expected the Down error of cluster a, got %v", err)
+	}
+	// Both clusters are torn down despite a failing.
+	for _, f := range []*fakeClusterDeployer{a, b} {
+		if expected := []string{f.kubeconfig, f.kubeconfig}; !reflect.DeepEqual(f.seen, expected) {
+			t.Errorf("expected calls with KUBECONFIG %v, got %v", expected, f.seen)
+		}
+	}
+	if len(merges) != 1 {
+		t.Errorf("expected no merge of an already merged kubeconfig, got %v", merges)
+	}
+	if actual := os.Getenv("KUBECONFIG"); actual != merged.Name() {
+		t.Errorf("expected KUBECONFIG to point at the merged kubeconfig, got %q", actual)
+	}
+
+	created, err := m.GetClusterCreated("")
+	if err != nil || !created.Equal(b.created) {
+		t.Errorf("expected the oldest creation time %v, got %v (%v)", b.created, created, err)
+	}
+}
+
+func TestMultiClusterDeployerMergesAfterFailedUp(t *testing.T) {
+	pre := os.Getenv("KUBECONFIG")
+	defer os.Setenv("KUBECONFIG", pre)
+	merged, err := ioutil.TempFile("", "merged")
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged.Close()
+	defer os.Remove(merged.Name())
+	preMerge := mergeKubeconfigs
+	defer func() { mergeKubeconfigs = preMerge }()
+	var merges int
+	mergeKubeconfigs = func(_ []string, dest string) error {
+		merges++
+		return ioutil.WriteFile(dest, []byte("merged"), 0600)
+	}
+
+	m := &multiClusterDeployer{
+		names:   []string{"a", "b"},
+		members: []deployer{&fakeClusterDeployer{kubeconfig: "/a"}, &fakeClusterDeployer{kubeconfig: "/b"}},
+		merged:  merged.Name(),
+	}
+	// Dumping the logs of clusters that never finished coming up still needs
+	// an up to date merged kubeconfig.
+	if err := m.DumpClusterLogs(t.TempDir(), ""); err != nil {
+		t.Errorf("unexpected DumpClusterLogs error: %v", err)
+	}
+	if err := m.Down(); err != nil {
+		t.Errorf("unexpected Down error: %v", err)
+	}
+	if merges != 1 {
+		t.Errorf("expected the empty kubeconfig to be merged once, got %d merges", merges)
+	}
+}
@@ -19,6 +19,7 @@ package process
 import (
 	"bytes"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -45,6 +46,16 @@ type Control struct {
 	Interrupt *time.Timer
 	Terminate *time.Timer
 
+	// phaseLock guards the fields describing the current phase, see RunPhase,
+	// and the CaptureOutput writer.
+	phaseLock    *sync.RWMutex
+	phase        string
+	phaseTimeout time.Duration
+	phaseTimer   *time.Timer
+	phaseExpired bool
+	phaseOutput  *tailBuffer
+	capture      io.Writer
+
 	verbose bool
 }
 
@@ -58,12 +69,117 @@ func NewControl(timeout time.Duration, interrupt, terminate *time.Timer, verbose
 		Timeout:     timeout,
 		Interrupt:   interrupt,
 		Terminate:   terminate,
+		phaseLock:   new(sync.RWMutex),
 		verbose:     verbose,
 	}
 }
 
+// phaseKillGrace is how long commands get to exit after their phase timed out
+// before they are killed.
+const phaseKillGrace = time.Minute
+
+// RunPhase returns f(), limiting the commands it runs to the timeout. When the
+// timeout expires, the running command is signalled and then killed, further
+// commands of the phase are skipped and the returned error names the phase.
+// A non-positive timeout runs f without a phase limit.
+func (c *Control) RunPhase(phase string, timeout time.Duration, f func() error) error {
+	_, err := c.runPhase(phase, timeout, f)
+	return err
+}
+
+// runPhase is RunPhase, additionally returning the tail of the output of the
+// commands the phase ran.
+func (c *Control) runPhase(phase string, timeout time.Duration, f func() error) (string, error) {
+	var timer *time.Timer
+	if timeout > 0 {
+		timer = time.NewTimer(timeout)
+	}
+	output := newTailBuffer(maxPhaseOutput)
+	c.phaseLock.Lock()
+	c.phase, c.phaseTimeout, c.phaseTimer, c.phaseExpired, c.phaseOutput = phase, timeout, timer, false, output
+	c.phaseLock.Unlock()
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+		c.phaseLock.Lock()
+		c.phase, c.phaseTimeout, c.phaseTimer, c.phaseExpired, c.phaseOutput = "", 0, nil, false, nil
+		c.phaseLock.Unlock()
+	}()
+
+	err := f()
+	if c.isPhaseExpired() {
+		if err == nil {
+			err = errors.New("commands were skipped")
+		}
+		err = fmt.Errorf("%s phase timed out after %s: %w", phase, timeout, err)
+	}
+	return output.String(), err
+}
+
+// CaptureOutput returns f(), additionally copying the displayed output of the
+// commands it runs to w.
+func (c *Control) CaptureOutput(w io.Writer, f func() error) error {
+	c.phaseLock.Lock()
+	c.capture = &syncWriter{w: w}
+	c.phaseLock.Unlock()
+	defer func() {
+		c.phaseLock.Lock()
+		c.capture = nil
+		c.phaseLock.Unlock()
+	}()
+	return f()
+}
+
+// phaseOutputWriter returns the writer capturing the output of the current
+// phase and any CaptureOutput writer, nil when nothing captures output.
+func (c *Control) phaseOutputWriter() io.Writer {
+	c.phaseLock.RLock()
+	defer c.phaseLock.RUnlock()
+	switch {
+	case c.phaseOutput != nil && c.capture != nil:
+		return io.MultiWriter(c.phaseOutput, c.capture)
+	case c.phaseOutput != nil:
+		return c.phaseOutput
+	default:
+		return c.capture
+	}
+}
+
+// phaseTimerC returns the channel of the current phase timer, nil (which
+// blocks forever) if there is no phase limit.
+func (c *Control) phaseTimerC() <-chan time.Time {
+	c.phaseLock.RLock()
+	defer c.phaseLock.RUnlock()
+	if c.phaseTimer == nil {
+		return nil
+	}
+	return c.phaseTimer.C
+}
+
+// expirePhase marks the current phase as timed out. It returns false if the
+// phase already expired before, i.e. the grace period is over as well.
+func (c *Control) expirePhase() (bool, string) {
+	c.phaseLock.Lock()
+	defer c.phaseLock.Unlock()
+	if c.phaseExpired {
+		return false, c.phase
+	}
+	c.phaseExpired = true
+	if c.phaseTimer != nil {
+		c.phaseTimer.Reset(phaseKillGrace)
+	}
+	return true, c.phase
+}
+
+func (c *Control) isPhaseExpired() bool {
+	c.phaseLock.RLock()
+	defer c.phaseLock.RUnlock()
+	return c.phaseExpired
+}
+
 // WriteXML creates a util.TestCase{} junit_runner.xml file inside the dump dir.
-func (c *Control) WriteXML(suite *util.TestSuite, dump string, start time.Time) {
+func (c *Control) WriteXML(suite *util.TestSuite, dump string, start time.Time) error {
 	// Note whether timeout occurred
 	tc := util.TestCase{
 		Name:      "Timeout",
@@ -79,32 +195,69 @@ func (c *Control) WriteXML(suite *util.TestSuite, dump string, start time.Time)
 	suite.Time = time.Since(start).Seconds()
 	out, err := xml.MarshalIndent(&suite, "", "    ")
 	if err != nil {
-		log.Fatalf("Could not marshal XML: %s", err)
+		return fmt.Errorf("could not marshal XML: %w", err)
 	}
 	path := filepath.Join(dump, "junit_runner.xml")
 	f, err := os.Create(path)
 	if err != nil {
-		log.Fatalf("Could not create file: %s", err)
+		return fmt.Errorf("could not create file: %w", err)
 	}
 	defer f.Close()
 	if _, err := f.WriteString(xml.Header); err != nil {
-		log.Fatalf("Error writing XML header: %s", err)
+		return fmt.Errorf("error writing XML header: %w", err)
 	}
 	if _, err := f.Write(out); err != nil {
-		log.Fatalf("Error writing XML data: %s", err)
+		return fmt.Errorf("error writing XML data: %w", err)
 	}
 	log.Printf("Saved XML output to %s.", path)
+	return nil
 }
 
 // XMLWrap returns f(), adding junit xml testcase result for name
 func (c *Control) XMLWrap(suite *util.TestSuite, name string, f func() error) error {
+	return c.xmlWrap(suite, "e2e.go", name, func() (string, error) { return "", f() })
+}
+
+// XMLWrapPhase records f() as a step of the phase, which it runs via RunPhase.
+//
+// The test case is classified by the phase (build, up, test, dump or down), so
+// that tooling can tell infrastructure failures from test failures, and on
+// failure includes the tail of the output of the commands the phase ran.
+// Errors are returned as a *PhaseError.
+func (c *Control) XMLWrapPhase(suite *util.TestSuite, phase, name string, timeout time.Duration, f func() error) error {
+	return c.xmlWrap(suite, phase, name, func() (string, error) {
+		output, err := c.runPhase(phase, timeout, f)
+		if err != nil {
+			err = &PhaseError{Phase: phase, Output: output, Err: err}
+		}
+		return output, err
+	})
+}
+
+// PhaseError is the error of a phase, along with the tail of the output of
+// the commands it ran.
+type PhaseError struct {
+	Phase  string
+	Output string
+	Err    error
+}
+
+func (e *PhaseError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PhaseError) Unwrap() error {
+	return e.Err
+}
+
+func (c *Control) xmlWrap(suite *util.TestSuite, className, name string, f func() (string, error)) error {
 	alreadyInterrupted := c.isInterrupted()
 	start := time.Now()
-	err := f()
+	output, err := f()
 	duration := time.Since(start)
 	tc := util.TestCase{
 		Name:      name,
-		ClassName: "e2e.go",
+		ClassName: className,
 		Time:      duration.Seconds(),
 	}
 	if err == nil && !alreadyInterrupted && c.isInterrupted() {
@@ -116,6 +269,7 @@ func (c *Control) XMLWrap(suite *util.TestSuite, name string, f func() error) er
 		} else {
 			tc.Skipped = err.Error()
 		}
+		tc.SystemOut = output
 		suite.Failures++
 	}
 
@@ -138,25 +292,66 @@ func (c *Control) isInterrupted() bool {
 	return i
 }
 
+// Stopped returns whether running commands were interrupted or terminated
+// because the timeout expired.
+func (c *Control) Stopped() bool {
+	return c.isInterrupted() || c.isTerminated()
+}
+
 // FinishRunning returns cmd.Wait() and/or times out.
 func (c *Control) FinishRunning(cmd *exec.Cmd) error {
 	stepName := strings.Join(cmd.Args, " ")
 	if c.isTerminated() {
 		return fmt.Errorf("skipped %s (kubetest is terminated)", stepName)
 	}
+	if c.isPhaseExpired() {
+		return fmt.Errorf("skipped %s (phase timed out)", stepName)
+	}
 	if cmd.Stdout == nil && c.verbose {
 		cmd.Stdout = os.Stdout
 	}
 	if cmd.Stderr == nil && c.verbose {
 		cmd.Stderr = os.Stderr
 	}
+	var pipes []*os.File
+	var copies []<-chan struct{}
+	if output := c.phaseOutputWriter(); output != nil {
+		// Only capture what is displayed anyway, callers may parse secrets from
+		// the output they capture themselves.
+		if cmd.Stdout == nil || cmd.Stdout == os.Stdout {
+			pw, done, err := teeOutput(cmd.Stdout, output)
+			if err != nil {
+				return fmt.Errorf("error capturing output of %v: %w", stepName, err)
+			}
+			cmd.Stdout = pw
+			pipes = append(pipes, pw)
+			copies = append(copies, done)
+		}
+		if cmd.Stderr == nil || cmd.Stderr == os.Stderr {
+			pw, done, err := teeOutput(cmd.Stderr, output)
+			if err != nil {
+				for _, p := range pipes {
+					p.Close()
+				}
+				return fmt.Errorf("error capturing output of %v: %w", stepName, err)
+			}
+			cmd.Stderr = pw
+			pipes = append(pipes, pw)
+			copies = append(copies, done)
+		}
+	}
 	log.Printf("Running: %v", stepName)
 	defer func(start time.Time) {
 		log.Printf("Step '%s' finished in %s", stepName, time.Since(start))
 	}(time.Now())
 
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
-	if err := cmd.Start(); err != nil {
+	err := cmd.Start()
+	// The command holds its own copies of the pipes now.
+	for _, p := range pipes {
+		p.Close()
+	}
+	if err != nil {
 		return fmt.Errorf("error starting %v: %w", stepName, err)
 	}
 
@@ -166,11 +361,28 @@ func (c *Control) FinishRunning(cmd *exec.Cmd) error {
 	signal.Notify(sigChannel, os.Interrupt)
 
 	go func() {
-		finished <- cmd.Wait()
+		err := cmd.Wait()
+		waitForOutput(stepName, copies)
+		finished <- err
 	}()
 
+	phaseTimeout := c.phaseTimerC()
 	for {
 		select {
+		case <-phaseTimeout:
+			pgid := getGroupPid(cmd.Process.Pid)
+			if first, phase := c.expirePhase(); first {
+				log.Printf("Interrupt %s after %s phase timed out. Will kill in another %s", stepName, phase, phaseKillGrace)
+				if err := syscall.Kill(-pgid, syscall.SIGINT); err != nil {
+					log.Printf("Failed to interrupt %s. Will kill immediately: %v", stepName, err)
+					syscall.Kill(-pgid, syscall.SIGKILL)
+				}
+			} else {
+				log.Printf("Killing %s, %s phase timed out %s ago", stepName, phase, phaseKillGrace)
+				if err := syscall.Kill(-pgid, syscall.SIGKILL); err != nil {
+					log.Printf("Failed to kill %v: %v", stepName, err)
+				}
+			}
 		case <-sigChannel:
 			log.Printf("Killing %v(%v) after receiving signal", stepName, -cmd.Process.Pid)
 
@@ -211,6 +423,8 @@ func (c *Control) FinishRunning(cmd *exec.Cmd) error {
 					suffix = " (terminated)"
 				} else if c.isInterrupted() {
 					suffix = " (interrupted)"
+				} else if c.isPhaseExpired() {
+					suffix = " (phase timed out)"
 				}
 				return fmt.Errorf("error during %s%s: %w", stepName, suffix, err)
 			}
@@ -307,8 +521,25 @@ func (c *Control) FinishRunningParallel(cmds ...*exec.Cmd) error {
 	}()
 
 	cmdFailed := false
+	phaseTimeout := c.phaseTimerC()
 	for {
 		select {
+		case <-phaseTimeout:
+			if first, phase := c.expirePhase(); first {
+				log.Printf("Abort parallel commands after %s phase timed out. Will kill in another %s", phase, phaseKillGrace)
+				select {
+				case <-intChan:
+				default:
+					close(intChan)
+				}
+			} else {
+				select {
+				case <-termChan:
+				default:
+					close(termChan)
+				}
+			}
+
 		case <-c.Terminate.C:
 			c.termLock.Lock()
 			c.terminated = true
@@ -325,7 +556,11 @@ func (c *Control) FinishRunningParallel(cmds ...*exec.Cmd) error {
 			c.interrupted = true
 			c.intLock.Unlock()
 			c.Terminate.Reset(15 * time.Minute)
-			close(intChan)
+			select {
+			case <-intChan:
+			default:
+				close(intChan)
+			}
 
 		case result, ok := <-resultChan:
 			if !ok {
@@ -377,6 +612,93 @@ func (c *Control) NoOutput(cmd *exec.Cmd) error {
 	return c.FinishRunning(cmd)
 }
 
+// maxPhaseOutput is how many bytes of command output a phase keeps to report
+// on failure.
+const maxPhaseOutput = 10 * 1024
+
+// tailBuffer is an io.Writer keeping the last max bytes written to it.
+type tailBuffer struct {
+	lock sync.Mutex
+	max  int
+	buf  []byte
+}
+
+func newTailBuffer(max int) *tailBuffer {
+	return &tailBuffer{max: max}
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.buf = append(t.buf, p...)
+	if extra := len(t.buf) - t.max; extra > 0 {
+		t.buf = append(t.buf[:0], t.buf[extra:]...)
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return string(t.buf)
+}
+
+// syncWriter serializes writes to w, as the stdout and stderr of a command
+// are copied concurrently.
+type syncWriter struct {
+	lock sync.Mutex
+	w    io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.w.Write(p)
+}
+
+// teeWriter returns a writer duplicating writes to w, if set, and tail.
+func teeWriter(w, tail io.Writer) io.Writer {
+	if w == nil {
+		return tail
+	}
+	return io.MultiWriter(w, tail)
+}
+
+// outputDrainTimeout is how long FinishRunning waits, once the command has
+// exited, for background processes that inherited its output to close it.
+var outputDrainTimeout = 10 * time.Second
+
+// teeOutput returns a pipe copying to w, if set, and tail, and a channel closed
+// once the copy is done. Unlike an io.Writer set as cmd.Stdout, the pipe is
+// inherited by the command, so cmd.Wait does not block on background children
+// keeping it open. The caller must close the returned file after cmd.Start.
+func teeOutput(w, tail io.Writer) (*os.File, <-chan struct{}, error) {
+	r, pw, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer r.Close()
+		io.Copy(teeWriter(w, tail), r)
+	}()
+	return pw, done, nil
+}
+
+// waitForOutput waits up to outputDrainTimeout for every copy to be done.
+func waitForOutput(stepName string, copies []<-chan struct{}) {
+	timeout := time.After(outputDrainTimeout)
+	for _, done := range copies {
+		select {
+		case <-done:
+		case <-timeout:
+			log.Printf("Not waiting for background processes of %s still writing output", stepName)
+			return
+		}
+	}
+}
+
 // getGroupPid gets the process group to kill the entire main/child process
 // if Getpgid return error use the current process Pid
 func getGroupPid(pid int) int {
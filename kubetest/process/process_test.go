@@ -367,3 +367,149 @@ func TestOutputOutputs(t *testing.T) {
 		t.Errorf("output() did not echo hello world: %v", txt)
 	}
 }
+
+func TestRunPhase(t *testing.T) {
+	interrupt := time.NewTimer(time.Hour)
+	terminate := time.NewTimer(time.Hour)
+	c := NewControl(time.Hour, interrupt, terminate, false)
+
+	start := time.Now()
+	err := c.RunPhase("up", 100*time.Millisecond, func() error {
+		if err := c.FinishRunning(exec.Command("sleep", "30")); err == nil {
+			t.Error("expected sleep to be interrupted")
+		}
+		return c.FinishRunning(exec.Command("echo", "skipped"))
+	})
+	if err == nil || !strings.Contains(err.Error(), "up phase timed out after 100ms") {
+		t.Errorf("expected error naming the timed out phase, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("phase timeout did not stop the command, took %s", elapsed)
+	}
+
+	if err := c.RunPhase("test", time.Hour, func() error {
+		return c.FinishRunning(exec.Command("echo", "hello world"))
+	}); err != nil {
+		t.Errorf("expected next phase to run normally, got: %v", err)
+	}
+	if err := c.RunPhase("down", 0, func() error { return nil }); err != nil {
+		t.Errorf("expected phase without timeout to succeed, got: %v", err)
+	}
+}
+
+func TestXMLWrapPhase(t *testing.T) {
+	interrupt := time.NewTimer(time.Hour)
+	terminate := time.NewTimer(time.Hour)
+	c := NewControl(time.Hour, interrupt, terminate, false)
+
+	suite := util.TestSuite{}
+	if err := c.XMLWrapPhase(&suite, "build", "Build", 0, func() error {
+		return c.FinishRunning(exec.Command("echo", "built"))
+	}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	err := c.XMLWrapPhase(&suite, "up", "Up", 0, func() error {
+		return c.FinishRunning(exec.Command("sh", "-c", "echo quota exceeded >&2; exit 1"))
+	})
+	var phaseErr *PhaseError
+	if !errors.As(err, &phaseErr) {
+		t.Fatalf("expected up to fail with a PhaseError, got %v", err)
+	}
+	if phaseErr.Phase != "up" || !strings.Contains(phaseErr.Output, "quota exceeded") {
+		t.Errorf("unexpected PhaseError: %+v", phaseErr)
+	}
+
+	if suite.Tests != 2 || suite.Failures != 1 {
+		t.Fatalf("expected 2 tests with 1 failure, got %d tests with %d failures", suite.Tests, suite.Failures)
+	}
+	build, up := suite.Cases[0], suite.Cases[1]
+	if build.ClassName != "build" || build.Name != "Build" || build.Failure != "" || build.SystemOut != "" {
+		t.Errorf("unexpected build case: %+v", build)
+	}
+	if up.ClassName != "up" || up.Name != "Up" || up.Failure == "" {
+		t.Errorf("unexpected up case: %+v", up)
+	}
+	if !strings.Contains(up.SystemOut, "quota exceeded") {
+		t.Errorf("expected up case to include the command output, got %q", up.SystemOut)
+	}
+}
+
+func TestCaptureOutput(t *testing.T) {
+	interrupt := time.NewTimer(time.Hour)
+	terminate := time.NewTimer(time.Hour)
+	c := NewControl(time.Hour, interrupt, terminate, false)
+
+	var b strings.Builder
+	err := c.RunPhase("test", 0, func() error {
+		return c.CaptureOutput(&b, func() error {
+			return c.FinishRunning(exec.Command("sh", "-c", "echo out; echo err >&2"))
+		})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := b.String(); !strings.Contains(got, "out") || !strings.Contains(got, "err") {
+		t.Errorf("expected stdout and stderr to be captured, got %q", got)
+	}
+
+	b.Reset()
+	if err := c.FinishRunning(exec.Command("echo", "uncaptured")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := b.String(); got != "" {
+		t.Errorf("expected nothing captured after CaptureOutput returned, got %q", got)
+	}
+}
+
+func TestCapturedOutputNotInPhase(t *testing.T) {
+	interrupt := time.NewTimer(time.Hour)
+	terminate := time.NewTimer(time.Hour)
+	c := NewControl(time.Hour, interrupt, terminate, false)
+
+	suite := util.TestSuite{}
+	c.XMLWrapPhase(&suite, "up", "Up", 0, func() error {
+		token, err := c.Output(exec.Command("echo", "secret-token"))
+		if err != nil || !strings.Contains(string(token), "secret-token") {
+			t.Errorf("expected Output to return the token, got %q, %v", token, err)
+		}
+		return c.FinishRunning(exec.Command("sh", "-c", "echo displayed; exit 1"))
+	})
+	if len(suite.Cases) != 1 {
+		t.Fatalf("expected one case, got %d", len(suite.Cases))
+	}
+	if out := suite.Cases[0].SystemOut; strings.Contains(out, "secret-token") || !strings.Contains(out, "displayed") {
+		t.Errorf("expected only the displayed output to be captured, got %q", out)
+	}
+}
+
+func TestFinishRunningBackgroundChild(t *testing.T) {
+	interrupt := time.NewTimer(time.Hour)
+	terminate := time.NewTimer(time.Hour)
+	c := NewControl(time.Hour, interrupt, terminate, false)
+	defer func(timeout time.Duration) { outputDrainTimeout = timeout }(outputDrainTimeout)
+	outputDrainTimeout = 100 * time.Millisecond
+
+	start := time.Now()
+	err := c.RunPhase("up", 0, func() error {
+		// The background sleep keeps the output open after the shell exits.
+		return c.FinishRunning(exec.Command("sh", "-c", "sleep 10 & echo started"))
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected FinishRunning not to wait for the background child, took %s", elapsed)
+	}
+}
+
+func TestTailBuffer(t *testing.T) {
+	b := newTailBuffer(5)
+	for _, s := range []string{"abc", "defg", "h"} {
+		if n, err := b.Write([]byte(s)); n != len(s) || err != nil {
+			t.Errorf("Write(%q) = %d, %v", s, n, err)
+		}
+	}
+	if got := b.String(); got != "defgh" {
+		t.Errorf("expected the last 5 bytes, got %q", got)
+	}
+}
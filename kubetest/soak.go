@@ -0,0 +1,158 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/test-infra/kubetest/util"
+)
+
+// soakLoop repeats the --test steps against the same cluster until
+// --soak-test-duration passes. Outside of --soak mode the tests run once.
+type soakLoop struct {
+	deadline  time.Time // zero unless soaking
+	dump      string
+	upload    string
+	iteration int
+	started   time.Time // when the current iteration started
+	failed    []int
+	now       func() time.Time
+	sleep     func(time.Duration)
+	stopped   func() bool
+}
+
+// soakFailureBackoff is the least time between the starts of an iteration
+// that failed and the next one, so that a broken cluster is not hammered.
+const soakFailureBackoff = time.Minute
+
+func newSoakLoop(o options, dump string) *soakLoop {
+	l := &soakLoop{dump: dump, upload: o.soakUpload, now: time.Now, sleep: time.Sleep, stopped: control.Stopped}
+	if o.soak && o.soakTestDuration > 0 {
+		l.deadline = l.now().Add(o.soakTestDuration)
+	}
+	return l
+}
+
+func (l *soakLoop) soaking() bool {
+	return !l.deadline.IsZero()
+}
+
+// next starts the next iteration, returning false once the tests should stop
+// because soaking is over or kubetest was interrupted or terminated.
+func (l *soakLoop) next() bool {
+	if l.iteration > 0 {
+		if !l.soaking() || l.stopped() {
+			return false
+		}
+		if l.lastFailed() {
+			wait := l.started.Add(soakFailureBackoff).Sub(l.now())
+			if remaining := l.deadline.Sub(l.now()); wait > remaining {
+				wait = remaining
+			}
+			if wait > 0 {
+				log.Printf("Soak iteration %d failed quickly, waiting %s before the next one", l.iteration, wait)
+				l.sleep(wait)
+			}
+		}
+		if !l.now().Before(l.deadline) || l.stopped() {
+			return false
+		}
+	}
+	l.iteration++
+	l.started = l.now()
+	if l.soaking() {
+		log.Printf("Starting soak iteration %d, soaking until %s", l.iteration, l.deadline)
+	}
+	return true
+}
+
+// lastFailed returns whether the current iteration failed.
+func (l *soakLoop) lastFailed() bool {
+	return len(l.failed) > 0 && l.failed[len(l.failed)-1] == l.iteration
+}
+
+// suffix distinguishes the steps of this iteration in junit_runner.xml.
+func (l *soakLoop) suffix() string {
+	if !l.soaking() {
+		return ""
+	}
+	return fmt.Sprintf(" (soak iteration %d)", l.iteration)
+}
+
+// dir is where this iteration reports its results, empty without --dump.
+func (l *soakLoop) dir() string {
+	if !l.soaking() || l.dump == "" {
+		return ""
+	}
+	return filepath.Join(l.dump, "soak-"+strconv.Itoa(l.iteration))
+}
+
+// testArgs points the --report-dir of args to this iteration's directory, so
+// iterations do not overwrite each other's results.
+func (l *soakLoop) testArgs(args []string) []string {
+	dir := l.dir()
+	if dir == "" {
+		return args
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Printf("Failed to create %s, reporting soak iteration %d to --dump: %v", dir, l.iteration, err)
+		return args
+	}
+	f, _, _ := util.ExtractField(args, "--report-dir")
+	return append(f, "--report-dir="+dir)
+}
+
+// finish records the result of this iteration and uploads its results to
+// --soak-upload, if set.
+func (l *soakLoop) finish(errs []error) error {
+	if !l.soaking() {
+		return nil
+	}
+	if len(errs) > 0 {
+		l.failed = append(l.failed, l.iteration)
+	}
+	dir := l.dir()
+	if l.upload == "" || dir == "" {
+		return nil
+	}
+	dest := strings.TrimSuffix(l.upload, "/") + "/" + filepath.Base(dir)
+	return control.XMLWrap(&suite, "Upload"+l.suffix(), func() error {
+		return control.FinishRunning(exec.Command("gsutil", "-m", "-q", "cp", "-r", dir, dest))
+	})
+}
+
+// summarize records the aggregate result of all soak iterations.
+func (l *soakLoop) summarize() error {
+	if !l.soaking() {
+		return nil
+	}
+	return control.XMLWrap(&suite, "Soak", func() error {
+		if len(l.failed) > 0 {
+			return fmt.Errorf("%d of %d soak iterations failed: %v", len(l.failed), l.iteration, l.failed)
+		}
+		log.Printf("All %d soak iterations passed", l.iteration)
+		return nil
+	})
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSoakLoopRunsOnceWithoutSoak(t *testing.T) {
+	l := newSoakLoop(options{soakTestDuration: time.Hour}, "/tmp/dump")
+	if !l.next() {
+		t.Fatal("expected a first iteration")
+	}
+	if l.suffix() != "" {
+		t.Errorf("expected no suffix, got %q", l.suffix())
+	}
+	args := []string{"--report-dir=/tmp/dump"}
+	if actual := l.testArgs(args); !reflect.DeepEqual(actual, args) {
+		t.Errorf("expected unchanged args, got %q", actual)
+	}
+	if err := l.finish(nil); err != nil {
+		t.Errorf("unexpected finish error: %v", err)
+	}
+	if l.next() {
+		t.Error("expected a single iteration")
+	}
+	if err := l.summarize(); err != nil {
+		t.Errorf("unexpected summarize error: %v", err)
+	}
+}
+
+func TestSoakLoop(t *testing.T) {
+	dump, err := ioutil.TempDir("", "soak")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dump)
+
+	now := time.Now()
+	l := newSoakLoop(options{soak: true, soakTestDuration: time.Hour}, dump)
+	l.now = func() time.Time { return now }
+
+	for i, fail := range []bool{false, true, false} {
+		if !l.next() {
+			t.Fatalf("expected iteration %d to run", i+1)
+		}
+		args := l.testArgs([]string{"--report-dir=" + dump, "--foo=bar"})
+		dir := filepath.Join(dump, "soak-"+strconv.Itoa(i+1))
+		if expected := []string{"--foo=bar", "--report-dir=" + dir}; !reflect.DeepEqual(args, expected) {
+			t.Errorf("iteration %d: expected args %q, got %q", i+1, expected, args)
+		}
+		if _, err := os.Stat(dir); err != nil {
+			t.Errorf("iteration %d: expected report dir to exist: %v", i+1, err)
+		}
+		var errs []error
+		if fail {
+			errs = append(errs, os.ErrNotExist)
+		}
+		if err := l.finish(errs); err != nil {
+			t.Errorf("iteration %d: unexpected finish error: %v", i+1, err)
+		}
+		now = now.Add(25 * time.Minute)
+	}
+	if l.next() {
+		t.Error("expected the loop to stop after the soak duration")
+	}
+	if l.suffix() != " (soak iteration 3)" {
+		t.Errorf("unexpected suffix %q", l.suffix())
+	}
+	if err := l.summarize(); err == nil || err.Error() != "1 of 3 soak iterations failed: [2]" {
+		t.Errorf("unexpected summarize error: %v", err)
+	}
+}
+
+func TestSoakLoopBacksOffAfterQuickFailures(t *testing.T) {
+	now := time.Now()
+	l := newSoakLoop(options{soak: true, soakTestDuration: time.Hour}, "")
+	l.now = func() time.Time { return now }
+	var slept []time.Duration
+	l.sleep = func(d time.Duration) {
+		slept = append(slept, d)
+		now = now.Add(d)
+	}
+
+	for i, fail := range []bool{true, false, false} {
+		if !l.next() {
+			t.Fatalf("expected iteration %d to run", i+1)
+		}
+		var errs []error
+		if fail {
+			errs = append(errs, os.ErrNotExist)
+		}
+		if err := l.finish(errs); err != nil {
+			t.Errorf("iteration %d: unexpected finish error: %v", i+1, err)
+		}
+		now = now.Add(10 * time.Second)
+	}
+	// Only the quick failure delays the next iteration.
+	if expected := []time.Duration{soakFailureBackoff - 10*time.Second}; !reflect.DeepEqual(slept, expected) {
+		t.Errorf("expected to sleep %v, slept %v", expected, slept)
+	}
+}
+
+func TestSoakLoopStopsWhenInterrupted(t *testing.T) {
+	l := newSoakLoop(options{soak: true, soakTestDuration: time.Hour}, "")
+	stopped := false
+	l.stopped = func() bool { return stopped }
+	if !l.next() {
+		t.Fatal("expected a first iteration")
+	}
+	stopped = true
+	if l.next() {
+		t.Error("expected the loop to stop once interrupted")
+	}
+}
@@ -18,6 +18,7 @@ package main
 
 import (
 	"fmt"
+	"log"
 	"os/exec"
 	"regexp"
 	"strings"
@@ -31,15 +32,19 @@ type stageStrategy struct {
 	gcsSuffix      string
 	versionSuffix  string
 	dockerRegistry string
+	marker         string
 }
 
 // Return something like gs://bucket/ci/suffix
 func (s *stageStrategy) String() string {
+	if !s.Enabled() {
+		return ""
+	}
 	p := "devel"
 	if s.ci {
 		p = "ci"
 	}
-	return fmt.Sprintf("%v%v%v", s.bucket, p, s.gcsSuffix)
+	return fmt.Sprintf("%v/%v%v", s.bucket, p, s.gcsSuffix)
 }
 
 // Parse bucket, ci, suffix from gs://BUCKET/ci/SUFFIX
@@ -94,5 +99,45 @@ func (s *stageStrategy) Stage(noAllowDup bool) error {
 
 	cmd := exec.Command(name, args...)
 	cmd.Dir = util.K8s("kubernetes")
-	return control.FinishRunning(cmd)
+	if err := control.FinishRunning(cmd); err != nil {
+		return err
+	}
+
+	version, err := s.version()
+	if err != nil {
+		return fmt.Errorf("failed to find staged version: %w", err)
+	}
+	url := "https://storage.googleapis.com/" + strings.TrimPrefix(s.String(), "gs://")
+	log.Printf("Staged %s to %s", version, url)
+	if s.marker != "" {
+		dest := fmt.Sprintf("%s/%s.txt", s.String(), s.marker)
+		if err := gcsWrite(dest, []byte(version)); err != nil {
+			return fmt.Errorf("failed to write %s: %w", dest, err)
+		}
+	}
+	// Point --up (and --save) at the staged release, unless --extract later replaces it.
+	return setReleaseEnv(url, version)
+}
+
+// Returns the version push-build.sh stages: the build version plus any --stage-suffix.
+func (s *stageStrategy) version() (string, error) {
+	gross := `. hack/lib/version.sh && KUBE_ROOT=. kube::version::get_version_vars && echo "${KUBE_GIT_VERSION-}"`
+	cmd := exec.Command("bash", "-c", gross)
+	cmd.Dir = util.K8s("kubernetes")
+	b, err := control.Output(cmd)
+	if err != nil {
+		return "", err
+	}
+	return stagedVersion(string(b), s.versionSuffix)
+}
+
+func stagedVersion(build, suffix string) (string, error) {
+	v := strings.TrimSpace(build)
+	if !strings.HasPrefix(v, "v") {
+		return "", fmt.Errorf("unexpected build version %q", v)
+	}
+	if suffix != "" {
+		v += "-" + suffix
+	}
+	return v, nil
 }
@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import "testing"
+
+func TestStageStrategy(t *testing.T) {
+	cases := []struct {
+		value   string
+		want    string
+		success bool
+	}{
+		{"gs://bucket/ci", "gs://bucket/ci", true},
+		{"gs://bucket/devel/job-suffix", "gs://bucket/devel/job-suffix", true},
+		{"gs://bucket/ci/a/b", "gs://bucket/ci/a/b", true},
+		{"gs://bucket/release", "", false},
+		{"bucket/ci", "", false},
+	}
+	for _, tc := range cases {
+		var s stageStrategy
+		err := s.Set(tc.value)
+		if tc.success && err != nil {
+			t.Errorf("Set(%q) failed: %v", tc.value, err)
+		}
+		if !tc.success && err == nil {
+			t.Errorf("Set(%q) unexpectedly succeeded", tc.value)
+		}
+		if got := s.String(); got != tc.want {
+			t.Errorf("Set(%q).String() = %q, want %q", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestStagedVersion(t *testing.T) {
+	cases := []struct {
+		build   string
+		suffix  string
+		want    string
+		success bool
+	}{
+		{"v1.20.0-alpha.1.23+abcdef0123\n", "", "v1.20.0-alpha.1.23+abcdef0123", true},
+		{"v1.20.0", "pull-123", "v1.20.0-pull-123", true},
+		{"", "", "", false},
+		{"unknown", "pull-123", "", false},
+	}
+	for _, tc := range cases {
+		got, err := stagedVersion(tc.build, tc.suffix)
+		if tc.success && err != nil {
+			t.Errorf("stagedVersion(%q, %q) failed: %v", tc.build, tc.suffix, err)
+		}
+		if !tc.success && err == nil {
+			t.Errorf("stagedVersion(%q, %q) unexpectedly succeeded", tc.build, tc.suffix)
+		}
+		if got != tc.want {
+			t.Errorf("stagedVersion(%q, %q) = %q, want %q", tc.build, tc.suffix, got, tc.want)
+		}
+	}
+}
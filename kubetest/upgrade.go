@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"k8s.io/test-infra/kubetest/util"
+)
+
+// upgradeStep is a step of an upgrade/downgrade test run, reported as its own
+// junit phase.
+type upgradeStep struct {
+	name  string // testcase name
+	phase string // junit classname and --report-prefix
+	args  string // e2e.test args
+	// skew runs the step from ../kubernetes_skew, the version being upgraded to.
+	skew bool
+	// mutates marks steps changing the cluster version, after which a failure
+	// makes the remaining steps meaningless.
+	mutates bool
+}
+
+// upgradeSteps returns the upgrade/downgrade steps the flags request, in the
+// order they run: test version A, upgrade to version B (run from the skew
+// directory), test version B, downgrade back to version A and test it again.
+func upgradeSteps(o options) []upgradeStep {
+	all := []upgradeStep{
+		{name: "PreUpgradeTest", phase: "pre-upgrade", args: o.preUpgradeTestArgs},
+		{name: "UpgradeTest", phase: "upgrade", args: o.upgradeArgs, skew: true, mutates: true},
+		{name: "PostUpgradeTest", phase: "post-upgrade", args: o.postUpgradeTestArgs, skew: true},
+		{name: "DowngradeTest", phase: "downgrade", args: o.downgradeArgs, mutates: true},
+		{name: "PostDowngradeTest", phase: "post-downgrade", args: o.postDowngradeTestArgs},
+	}
+	var steps []upgradeStep
+	for _, s := range all {
+		if s.args != "" {
+			steps = append(steps, s)
+		}
+	}
+	return steps
+}
+
+// runUpgradeSteps runs the requested upgrade/downgrade steps against the
+// cluster, recording each step that passes in the checkpoint.
+func runUpgradeSteps(deploy deployer, o options, dump string, cp *checkpoint) []error {
+	steps := resumeUpgradeSteps(upgradeSteps(o), cp)
+	if len(steps) == 0 {
+		return nil
+	}
+	if err := control.XMLWrap(&suite, "test setup", deploy.TestSetup); err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	for i, s := range steps {
+		err := control.XMLWrapPhase(&suite, s.phase, s.name, o.testTimeout, func() error {
+			return s.run(o, dump)
+		})
+		errs = util.AppendError(errs, err)
+		if err == nil {
+			errs = util.AppendError(errs, cp.record(s.checkpointPhase()))
+		}
+		if err != nil && s.mutates && i+1 < len(steps) {
+			var skipped []string
+			for _, r := range steps[i+1:] {
+				skipped = append(skipped, r.name)
+			}
+			return append(errs, fmt.Errorf("skipped %s after %s failed", strings.Join(skipped, ", "), s.name))
+		}
+	}
+	return errs
+}
+
+// resumeUpgradeSteps drops the steps up to the last one that passed in a
+// previous run, as the cluster already moved past them.
+func resumeUpgradeSteps(steps []upgradeStep, cp *checkpoint) []upgradeStep {
+	for i := len(steps) - 1; i >= 0; i-- {
+		if cp.done(steps[i].checkpointPhase()) {
+			log.Printf("Upgrade steps up to %s finished in a previous run, skipping them", steps[i].name)
+			return steps[i+1:]
+		}
+	}
+	return steps
+}
+
+// checkpointPhase is the checkpoint phase recording that the step passed.
+func (s upgradeStep) checkpointPhase() string {
+	return "upgrade/" + s.phase
+}
+
+func (s upgradeStep) run(o options, dump string) error {
+	args := argFields(s.args, dump, o.clusterIPRange)
+	var env []string // nil inherits the kubetest environment
+	if s.mutates {
+		// upgrade tests really only run one spec
+		for _, v := range os.Environ() {
+			if !strings.HasPrefix(v, "GINKGO_PARALLEL") {
+				env = append(env, v)
+			}
+		}
+	}
+	if s.skew {
+		return skewTestEnv(env, args, s.phase, o.checkSkew)
+	}
+	cmd := exec.Command("./hack/ginkgo-e2e.sh", util.AppendField(args, "--report-prefix", s.phase)...)
+	cmd.Env = env
+	return control.FinishRunning(cmd)
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUpgradeSteps(t *testing.T) {
+	cases := []struct {
+		name     string
+		o        options
+		expected []string
+	}{
+		{
+			name: "no upgrade",
+		},
+		{
+			name:     "upgrade only",
+			o:        options{upgradeArgs: "--ginkgo.focus=Upgrade"},
+			expected: []string{"upgrade"},
+		},
+		{
+			name: "full round trip",
+			o: options{
+				preUpgradeTestArgs:    "--ginkgo.focus=Pre",
+				upgradeArgs:           "--ginkgo.focus=Upgrade",
+				postUpgradeTestArgs:   "--ginkgo.focus=Post",
+				downgradeArgs:         "--ginkgo.focus=Downgrade",
+				postDowngradeTestArgs: "--ginkgo.focus=PostDowngrade",
+			},
+			expected: []string{"pre-upgrade", "upgrade", "post-upgrade", "downgrade", "post-downgrade"},
+		},
+		{
+			name: "downgrade without upgrade",
+			o: options{
+				downgradeArgs:         "--ginkgo.focus=Downgrade",
+				postDowngradeTestArgs: "--ginkgo.focus=PostDowngrade",
+			},
+			expected: []string{"downgrade", "post-downgrade"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual []string
+			for _, s := range upgradeSteps(tc.o) {
+				actual = append(actual, s.phase)
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected phases %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestResumeUpgradeSteps(t *testing.T) {
+	o := options{
+		preUpgradeTestArgs:  "--ginkgo.focus=Pre",
+		upgradeArgs:         "--ginkgo.focus=Upgrade",
+		postUpgradeTestArgs: "--ginkgo.focus=Post",
+	}
+	cases := []struct {
+		name      string
+		completed []string
+		expected  []string
+	}{
+		{
+			name:     "fresh run",
+			expected: []string{"pre-upgrade", "upgrade", "post-upgrade"},
+		},
+		{
+			name:      "pre-upgrade tests passed",
+			completed: []string{"upgrade/pre-upgrade"},
+			expected:  []string{"upgrade", "post-upgrade"},
+		},
+		{
+			name:      "upgrade passed after failed pre-upgrade tests",
+			completed: []string{"upgrade/upgrade"},
+			expected:  []string{"post-upgrade"},
+		},
+		{
+			name:      "all steps passed",
+			completed: []string{"upgrade/pre-upgrade", "upgrade/upgrade", "upgrade/post-upgrade"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "upgrade")
+			if err != nil {
+				t.Fatalf("Failed to create temp dir: %v", err)
+			}
+			defer os.RemoveAll(dir)
+			cp, err := newCheckpoint(filepath.Join(dir, "state.json"), true)
+			if err != nil {
+				t.Fatalf("Failed to create checkpoint: %v", err)
+			}
+			for _, phase := range tc.completed {
+				if err := cp.record(phase); err != nil {
+					t.Fatalf("Failed to record %q: %v", phase, err)
+				}
+			}
+			var actual []string
+			for _, s := range resumeUpgradeSteps(upgradeSteps(o), cp) {
+				actual = append(actual, s.phase)
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected phases %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRunUpgradeStepsSkipsCompletedSteps(t *testing.T) {
+	dir, err := ioutil.TempDir("", "upgrade")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	cp, err := newCheckpoint(filepath.Join(dir, "state.json"), true)
+	if err != nil {
+		t.Fatalf("Failed to create checkpoint: %v", err)
+	}
+	if err := cp.record("upgrade/upgrade"); err != nil {
+		t.Fatalf("Failed to record phase: %v", err)
+	}
+	// A nil deployer panics if the completed upgrade reaches TestSetup.
+	if errs := runUpgradeSteps(nil, options{upgradeArgs: "--ginkgo.focus=Upgrade"}, "", cp); len(errs) != 0 {
+		t.Errorf("Expected completed upgrade steps to be skipped, got %v", errs)
+	}
+}
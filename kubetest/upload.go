@@ -0,0 +1,225 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"k8s.io/test-infra/kubetest/util"
+)
+
+// gcsUploadAttempts is how many times an upload to GCS is tried before giving up.
+const gcsUploadAttempts = 3
+
+// gcsResults uploads started.json, finished.json, the build log and the
+// artifacts of a run to GCS, as prow's pod utilities do for decorated jobs.
+type gcsResults struct {
+	path    string // gs:// path of this run
+	dump    string // absolute --dump directory, uploaded as artifacts
+	started time.Time
+
+	buildLog       *os.File
+	stdout, stderr *os.File // the originals, restored by finish
+	pipes          []*os.File
+	copied         chan error
+}
+
+// gcsResultsPath returns the canonical path of the run described by the prow
+// job environment variables below bucket.
+func gcsResultsPath(bucket string, getenv func(string) string) (string, error) {
+	if !strings.HasPrefix(bucket, "gs://") {
+		return "", fmt.Errorf("bucket %q must start with gs://", bucket)
+	}
+	job, build := getenv("JOB_NAME"), getenv("BUILD_ID")
+	if job == "" || build == "" {
+		return "", errors.New("JOB_NAME and BUILD_ID must be set to upload results")
+	}
+	bucket = strings.TrimSuffix(bucket, "/")
+	switch jobType := getenv("JOB_TYPE"); jobType {
+	case "presubmit":
+		org, repo, pull := getenv("REPO_OWNER"), getenv("REPO_NAME"), getenv("PULL_NUMBER")
+		if org == "" || repo == "" || pull == "" {
+			return "", errors.New("REPO_OWNER, REPO_NAME and PULL_NUMBER must be set to upload presubmit results")
+		}
+		return strings.Join([]string{bucket, "pr-logs", "pull", org + "_" + repo, pull, job, build}, "/"), nil
+	case "batch":
+		return strings.Join([]string{bucket, "pr-logs", "pull", "batch", job, build}, "/"), nil
+	case "", "periodic", "postsubmit":
+		return strings.Join([]string{bucket, "logs", job, build}, "/"), nil
+	default:
+		return "", fmt.Errorf("unknown JOB_TYPE %q", jobType)
+	}
+}
+
+// startGCSResults uploads started.json below bucket and starts capturing the
+// build log.
+func startGCSResults(bucket, dump string) (*gcsResults, error) {
+	path, err := gcsResultsPath(bucket, os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+	// Resolve dump before kubetest changes into the kubernetes directory.
+	if dump, err = util.OptionalAbsPath(dump); err != nil {
+		return nil, err
+	}
+	r := &gcsResults{path: path, dump: dump, started: time.Now()}
+	if err := r.captureBuildLog(); err != nil {
+		return nil, fmt.Errorf("error capturing build log: %w", err)
+	}
+	log.Printf("Uploading results to %s", path)
+
+	started := map[string]interface{}{
+		"timestamp": r.started.Unix(),
+	}
+	if node, err := os.Hostname(); err == nil {
+		started["node"] = node
+	}
+	if refs := os.Getenv("PULL_REFS"); refs != "" {
+		started["pull"] = refs
+	}
+	if err := r.uploadJSON("started.json", started); err != nil {
+		r.stopBuildLog()
+		return nil, err
+	}
+	return r, nil
+}
+
+// captureBuildLog tees the stdout and stderr of kubetest, and of the commands
+// it runs, to the build log.
+func (r *gcsResults) captureBuildLog() error {
+	f, err := ioutil.TempFile("", "build-log")
+	if err != nil {
+		return err
+	}
+	r.buildLog = f
+	r.stdout, r.stderr = os.Stdout, os.Stderr
+	r.copied = make(chan error, 2)
+
+	tee := func(original *os.File) (*os.File, error) {
+		pr, pw, err := os.Pipe()
+		if err != nil {
+			return nil, err
+		}
+		r.pipes = append(r.pipes, pw)
+		go func() {
+			_, err := io.Copy(io.MultiWriter(original, f), pr)
+			r.copied <- err
+		}()
+		return pw, nil
+	}
+	stdout, err := tee(os.Stdout)
+	if err != nil {
+		return err
+	}
+	stderr, err := tee(os.Stderr)
+	if err != nil {
+		stdout.Close()
+		<-r.copied
+		return err
+	}
+	os.Stdout, os.Stderr = stdout, stderr
+	log.SetOutput(os.Stderr)
+	return nil
+}
+
+// stopBuildLog restores stdout and stderr and waits for the build log to be written.
+func (r *gcsResults) stopBuildLog() error {
+	os.Stdout, os.Stderr = r.stdout, r.stderr
+	log.SetOutput(os.Stderr)
+	var errs []error
+	for _, p := range r.pipes {
+		errs = util.AppendError(errs, p.Close())
+	}
+	for range r.pipes {
+		errs = util.AppendError(errs, <-r.copied)
+	}
+	errs = util.AppendError(errs, r.buildLog.Close())
+	if len(errs) > 0 {
+		return fmt.Errorf("encountered %d errors: %v", len(errs), errs)
+	}
+	return nil
+}
+
+// finish uploads the build log, the dump directory and finished.json.
+func (r *gcsResults) finish(passed bool) error {
+	var errs []error
+	errs = util.AppendError(errs, r.stopBuildLog())
+	defer os.Remove(r.buildLog.Name())
+
+	errs = util.AppendError(errs, gcsRetry(func() error {
+		return control.FinishRunning(exec.Command("gsutil", "-q", "cp", r.buildLog.Name(), r.path+"/build-log.txt"))
+	}))
+	if r.dump != "" {
+		errs = util.AppendError(errs, gcsRetry(func() error {
+			// rsync only copies what previous attempts missed, -c compares
+			// checksums rather than modification times to tell.
+			return control.FinishRunning(exec.Command("gsutil", "-m", "-q", "rsync", "-c", "-r", r.dump, r.path+"/artifacts"))
+		}))
+	}
+
+	result := "FAILURE"
+	if passed {
+		result = "SUCCESS"
+	}
+	errs = util.AppendError(errs, r.uploadJSON("finished.json", map[string]interface{}{
+		"timestamp": time.Now().Unix(),
+		"passed":    passed,
+		"result":    result,
+		"metadata": map[string]string{
+			"kubetest-version": gitTag,
+		},
+	}))
+	if len(errs) > 0 {
+		return fmt.Errorf("encountered %d errors: %v", len(errs), errs)
+	}
+	return nil
+}
+
+func (r *gcsResults) uploadJSON(name string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error marshalling %s: %w", name, err)
+	}
+	return gcsRetry(func() error {
+		return gcsWrite(r.path+"/"+name, b)
+	})
+}
+
+// gcsRetry calls upload up to gcsUploadAttempts times, backing off between attempts.
+func gcsRetry(upload func() error) error {
+	var err error
+	for i := 0; i < gcsUploadAttempts; i++ {
+		if err = upload(); err == nil {
+			return nil
+		}
+		if i < gcsUploadAttempts-1 {
+			backoff := time.Duration(1<<uint(i)) * 5 * time.Second
+			log.Printf("Upload failed, retrying in %s: %v", backoff, err)
+			sleep(backoff)
+		}
+	}
+	return fmt.Errorf("failed to upload after %d attempts: %w", gcsUploadAttempts, err)
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGCSResultsPath(t *testing.T) {
+	cases := []struct {
+		name     string
+		bucket   string
+		env      map[string]string
+		expected string
+		err      bool
+	}{
+		{
+			name:     "periodic",
+			bucket:   "gs://bucket/",
+			env:      map[string]string{"JOB_NAME": "ci-job", "BUILD_ID": "123", "JOB_TYPE": "periodic"},
+			expected: "gs://bucket/logs/ci-job/123",
+		},
+		{
+			name:     "no job type",
+			bucket:   "gs://bucket",
+			env:      map[string]string{"JOB_NAME": "ci-job", "BUILD_ID": "123"},
+			expected: "gs://bucket/logs/ci-job/123",
+		},
+		{
+			name:   "presubmit",
+			bucket: "gs://bucket",
+			env: map[string]string{
+				"JOB_NAME": "pull-job", "BUILD_ID": "123", "JOB_TYPE": "presubmit",
+				"REPO_OWNER": "kubernetes", "REPO_NAME": "test-infra", "PULL_NUMBER": "42",
+			},
+			expected: "gs://bucket/pr-logs/pull/kubernetes_test-infra/42/pull-job/123",
+		},
+		{
+			name:     "batch",
+			bucket:   "gs://bucket",
+			env:      map[string]string{"JOB_NAME": "pull-job", "BUILD_ID": "123", "JOB_TYPE": "batch"},
+			expected: "gs://bucket/pr-logs/pull/batch/pull-job/123",
+		},
+		{
+			name:   "presubmit without pull",
+			bucket: "gs://bucket",
+			env:    map[string]string{"JOB_NAME": "pull-job", "BUILD_ID": "123", "JOB_TYPE": "presubmit"},
+			err:    true,
+		},
+		{
+			name:   "missing build",
+			bucket: "gs://bucket",
+			env:    map[string]string{"JOB_NAME": "ci-job"},
+			err:    true,
+		},
+		{
+			name:   "not gcs",
+			bucket: "bucket",
+			env:    map[string]string{"JOB_NAME": "ci-job", "BUILD_ID": "123"},
+			err:    true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := gcsResultsPath(tc.bucket, func(k string) string { return tc.env[k] })
+			switch {
+			case tc.err && err == nil:
+				t.Errorf("expected an error, got %q", actual)
+			case !tc.err && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case actual != tc.expected:
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGCSRetry(t *testing.T) {
+	oldSleep := sleep
+	defer func() { sleep = oldSleep }()
+	var slept []time.Duration
+	sleep = func(d time.Duration) { slept = append(slept, d) }
+
+	calls := 0
+	if err := gcsRetry(func() error {
+		calls++
+		if calls < 2 {
+			return errors.New("transient")
+		}
+		return nil
+	}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if calls != 2 || len(slept) != 1 {
+		t.Errorf("expected 2 calls and 1 backoff, got %d calls and %v", calls, slept)
+	}
+
+	calls, slept = 0, nil
+	if err := gcsRetry(func() error {
+		calls++
+		return errors.New("permanent")
+	}); err == nil || !strings.Contains(err.Error(), "permanent") {
+		t.Errorf("expected the last error, got %v", err)
+	}
+	if calls != gcsUploadAttempts || len(slept) != gcsUploadAttempts-1 || slept[1] <= slept[0] {
+		t.Errorf("expected %d calls with growing backoffs, got %d calls and %v", gcsUploadAttempts, calls, slept)
+	}
+}
+
+func TestCaptureBuildLog(t *testing.T) {
+	stdout, stderr := os.Stdout, os.Stderr
+	r := &gcsResults{}
+	if err := r.captureBuildLog(); err != nil {
+		t.Fatalf("failed to capture build log: %v", err)
+	}
+	defer os.Remove(r.buildLog.Name())
+
+	fmt.Fprintln(os.Stdout, "hello stdout")
+	log.Print("hello log")
+	if err := r.stopBuildLog(); err != nil {
+		t.Errorf("failed to stop build log: %v", err)
+	}
+	if os.Stdout != stdout || os.Stderr != stderr {
+		t.Error("expected stdout and stderr to be restored")
+	}
+
+	b, err := ioutil.ReadFile(r.buildLog.Name())
+	if err != nil {
+		t.Fatalf("failed to read build log: %v", err)
+	}
+	for _, expected := range []string{"hello stdout", "hello log"} {
+		if !strings.Contains(string(b), expected) {
+			t.Errorf("expected build log to contain %q, got %q", expected, string(b))
+		}
+	}
+}
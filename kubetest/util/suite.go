@@ -30,6 +30,7 @@ type TestCase struct {
 	Time      float64  `xml:"time,attr"`
 	Failure   string   `xml:"failure,omitempty"`
 	Skipped   string   `xml:"skipped,omitempty"`
+	SystemOut string   `xml:"system-out,omitempty"`
 }
 
 // TestSuite holds a slice of TestCase and other summary metadata.
@@ -115,6 +115,8 @@ const (
 type options struct {
 	debug           bool
 	confirm         bool
+	deleteUnknown   bool
+	dryRun          bool
 	endpoint        flagutil.Strings
 	graphqlEndpoint string
 	labelsPath      string
@@ -137,12 +139,14 @@ func gatherOptions() (opts options, deprecatedOptions bool) {
 	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
 	fs.BoolVar(&o.debug, "debug", false, "Turn on debug to be more verbose")
 	fs.BoolVar(&o.confirm, "confirm", false, "Make mutating API calls to GitHub.")
+	fs.BoolVar(&o.deleteUnknown, "delete-unknown", false, "Delete labels that labels.yaml does not mention from the synced repos.")
+	fs.BoolVar(&o.dryRun, "dry-run", false, "Print the label changes each repo needs without making them, even with --confirm.")
 	o.endpoint = flagutil.NewStrings(github.DefaultAPIEndpoint)
 	fs.Var(&o.endpoint, "endpoint", "GitHub's API endpoint. DEPRECATED: use --github-endpoint")
 	fs.StringVar(&o.graphqlEndpoint, "graphql-endpoint", github.DefaultGraphQLEndpoint, "GitHub's GraphQL API endpoint. DEPRECATED: use --github-graphql-endpoint")
 	fs.StringVar(&o.labelsPath, "config", "", "Path to labels.yaml")
 	fs.StringVar(&o.onlyRepos, "only", "", "Only look at the following comma separated org/repos")
-	fs.StringVar(&o.orgs, "orgs", "", "Comma separated list of orgs to sync")
+	fs.StringVar(&o.orgs, "orgs", "", "Comma separated list of orgs to sync, defaults to the orgs labels.yaml configures")
 	fs.StringVar(&o.skipRepos, "skip", "", "Comma separated list of org/repos to skip syncing")
 	fs.StringVar(&o.token, "token", "", "Path to github oauth secret. DEPRECATED: use --github-token-path")
 	fs.StringVar(&o.action, "action", "sync", "One of: sync, docs")
@@ -335,6 +339,18 @@ func (c Configuration) validate(orgs string) error {
 	return nil
 }
 
+// orgNames returns the sorted orgs the configuration has org or repo labels for.
+func (c Configuration) orgNames() []string {
+	orgs := sets.NewString()
+	for org := range c.Orgs {
+		orgs.Insert(org)
+	}
+	for repo := range c.Repos {
+		orgs.Insert(strings.SplitN(repo, "/", 2)[0])
+	}
+	return orgs.List()
+}
+
 // LabelsForTarget returns labels that have a given target
 func LabelsForTarget(labels []Label, target LabelTarget) (filteredLabels []Label) {
 	for _, label := range labels {
@@ -468,9 +484,15 @@ func rename(repo string, previous, wanted Label) Update {
 }
 
 // Update the label color/description
-func change(repo string, label Label) Update {
-	logrus.WithField("repo", repo).WithField("label", label.Name).WithField("color", label.Color).Info("change")
-	return Update{Why: "change", Current: &label, Wanted: &label, repo: repo}
+func change(repo string, current, wanted Label) Update {
+	logrus.WithField("repo", repo).WithField("label", wanted.Name).WithField("color", wanted.Color).Info("change")
+	return Update{Why: "change", Current: &current, Wanted: &wanted, repo: repo}
+}
+
+// Delete a label the configuration does not know about
+func unknown(repo string, label Label) Update {
+	logrus.WithField("repo", repo).WithField("label", label.Name).Info("unknown")
+	return Update{Why: "unknown", Current: &label, repo: repo}
 }
 
 // Migrate labels to another label
@@ -504,6 +526,27 @@ func classifyLabels(labels []Label, required, archaic, dead map[string]Label, no
 	return newRequired, newArchaic, newDead
 }
 
+// knownLabels returns the lowercase names of every label config mentions for
+// the repo of org, including retired labels that are not deleted yet.
+func knownLabels(config Configuration, org, repo string) map[string]bool {
+	known := map[string]bool{}
+	addLabelNames(known, config.Default.Labels)
+	if orgLabels, ok := config.Orgs[org]; ok {
+		addLabelNames(known, orgLabels.Labels)
+	}
+	if repoconfig, ok := config.Repos[org+"/"+repo]; ok {
+		addLabelNames(known, repoconfig.Labels)
+	}
+	return known
+}
+
+func addLabelNames(names map[string]bool, labels []Label) {
+	for _, l := range labels {
+		names[strings.ToLower(l.Name)] = true
+		addLabelNames(names, l.Previously)
+	}
+}
+
 func copyLabelMap(originalMap map[string]Label) map[string]Label {
 	newMap := make(map[string]Label)
 	for k, v := range originalMap {
@@ -512,7 +555,9 @@ func copyLabelMap(originalMap map[string]Label) map[string]Label {
 	return newMap
 }
 
-func syncLabels(config Configuration, org string, repos RepoLabels) (RepoUpdates, error) {
+// syncLabels returns the updates that bring the labels of the repos of org in line with config.
+// When deleteUnknown is set this includes deleting every label config does not mention.
+func syncLabels(config Configuration, org string, repos RepoLabels, deleteUnknown bool) (RepoUpdates, error) {
 	// Find required, dead and archaic labels
 	defaultRequired, defaultArchaic, defaultDead := classifyLabels(config.Default.Labels, make(map[string]Label), make(map[string]Label), make(map[string]Label), time.Now(), nil)
 	if orgLabels, ok := config.Orgs[org]; ok {
@@ -585,9 +630,18 @@ func syncLabels(config Configuration, org string, repos RepoLabels) (RepoUpdates
 			case l.Name != cur.Name:
 				actions = append(actions, rename(repo, cur, l))
 			case l.Color != cur.Color:
-				actions = append(actions, change(repo, l))
+				actions = append(actions, change(repo, cur, l))
 			case l.Description != cur.Description:
-				actions = append(actions, change(repo, l))
+				actions = append(actions, change(repo, cur, l))
+			}
+		}
+
+		if deleteUnknown {
+			known := knownLabels(config, org, repo)
+			for _, l := range labels {
+				if !known[strings.ToLower(l.Name)] {
+					actions = append(actions, unknown(repo, l))
+				}
 			}
 		}
 
@@ -606,6 +660,48 @@ func syncLabels(config Configuration, org string, repos RepoLabels) (RepoUpdates
 	return u, overallErr
 }
 
+// Diff describes the updates for the repos of org, one per line and sorted by repo.
+func (ru RepoUpdates) Diff(org string) string {
+	var repos []string
+	for repo := range ru {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+
+	var b strings.Builder
+	for _, repo := range repos {
+		for _, u := range ru[repo] {
+			fmt.Fprintf(&b, "%s/%s: %s\n", org, repo, u.describe())
+		}
+	}
+	return b.String()
+}
+
+func (u Update) describe() string {
+	switch u.Why {
+	case "missing":
+		return fmt.Sprintf("+ create %q (color %s, description %q)", u.Wanted.Name, u.Wanted.Color, u.Wanted.Description)
+	case "change":
+		var changes []string
+		if u.Current.Color != u.Wanted.Color {
+			changes = append(changes, fmt.Sprintf("color %s -> %s", u.Current.Color, u.Wanted.Color))
+		}
+		if u.Current.Description != u.Wanted.Description {
+			changes = append(changes, fmt.Sprintf("description %q -> %q", u.Current.Description, u.Wanted.Description))
+		}
+		return fmt.Sprintf("~ change %q: %s", u.Wanted.Name, strings.Join(changes, ", "))
+	case "rename":
+		return fmt.Sprintf("~ rename %q -> %q (color %s, description %q)", u.Current.Name, u.Wanted.Name, u.Wanted.Color, u.Wanted.Description)
+	case "migrate":
+		return fmt.Sprintf("> migrate open issues and PRs from %q to %q", u.Current.Name, u.Wanted.Name)
+	case "dead":
+		return fmt.Sprintf("- delete %q (past deleteAfter)", u.Current.Name)
+	case "unknown":
+		return fmt.Sprintf("- delete %q (not in labels.yaml)", u.Current.Name)
+	}
+	return u.Why
+}
+
 type repoUpdate struct {
 	repo   string
 	update Update
@@ -650,7 +746,7 @@ func (ru RepoUpdates) DoUpdates(org string, gc client) error {
 					if err != nil {
 						errChan <- err
 					}
-				case "dead":
+				case "dead", "unknown":
 					err := gc.DeleteRepoLabel(org, repo, update.Current.Name)
 					if err != nil {
 						errChan <- err
@@ -745,6 +841,9 @@ func main() {
 	if o.debug {
 		logrus.SetLevel(logrus.DebugLevel)
 	}
+	if o.dryRun {
+		o.confirm = false
+	}
 
 	config, err := LoadConfig(o.labelsPath, o.orgs)
 	if err != nil {
@@ -793,7 +892,7 @@ func main() {
 				logrus.WithError(err).Fatal("invalid value for --only")
 			}
 			for org := range reposToSync {
-				if err = syncOrg(org, githubClient, *config, reposToSync[org], o.confirm); err != nil {
+				if err = syncOrg(org, githubClient, *config, reposToSync[org], o.confirm, o.deleteUnknown); err != nil {
 					logrus.WithError(err).Fatalf("failed to update %s", org)
 				}
 			}
@@ -809,7 +908,14 @@ func main() {
 			skippedRepos = reposToSkip
 		}
 
-		for _, org := range strings.Split(o.orgs, ",") {
+		orgs := strings.Split(o.orgs, ",")
+		if o.orgs == "" {
+			orgs = config.orgNames()
+		}
+		if len(orgs) == 0 {
+			logrus.Fatal("--orgs unset and no orgs configured in --config")
+		}
+		for _, org := range orgs {
 			org = strings.TrimSpace(org)
 			logger := logrus.WithField("org", org)
 			logger.Info("Reading repos")
@@ -820,7 +926,7 @@ func main() {
 			if skipped, exist := skippedRepos[org]; exist {
 				repos = sets.NewString(repos...).Difference(sets.NewString(skipped...)).UnsortedList()
 			}
-			if err = syncOrg(org, githubClient, *config, repos, o.confirm); err != nil {
+			if err = syncOrg(org, githubClient, *config, repos, o.confirm, o.deleteUnknown); err != nil {
 				logrus.WithError(err).Fatalf("failed to update %s", org)
 			}
 		}
@@ -926,7 +1032,7 @@ func linkify(text string) string {
 	return strings.ToLower(link)
 }
 
-func syncOrg(org string, githubClient client, config Configuration, repos []string, confirm bool) error {
+func syncOrg(org string, githubClient client, config Configuration, repos []string, confirm, deleteUnknown bool) error {
 	logger := logrus.WithField("org", org)
 	logger.Infof("Found %d repos", len(repos))
 	currLabels, err := loadLabels(githubClient, org, repos)
@@ -935,7 +1041,7 @@ func syncOrg(org string, githubClient client, config Configuration, repos []stri
 	}
 
 	logger.Infof("Syncing labels for %d repos", len(repos))
-	updates, err := syncLabels(config, org, *currLabels)
+	updates, err := syncLabels(config, org, *currLabels, deleteUnknown)
 	if err != nil {
 		return err
 	}
@@ -944,6 +1050,7 @@ func syncOrg(org string, githubClient client, config Configuration, repos []stri
 	logger.Debug(string(y))
 
 	if !confirm {
+		fmt.Print(updates.Diff(org))
 		logger.Infof("Running without --confirm, no mutations made")
 		return nil
 	}
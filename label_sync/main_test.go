@@ -131,10 +131,12 @@ func TestValidate(t *testing.T) {
 // Output: list of wanted label updates (update due to name or color) addition due to missing labels
 // This is main testing for this program
 func TestSyncLabels(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour)
 	var testcases = []struct {
 		name            string
 		config          Configuration
 		current         RepoLabels
+		deleteUnknown   bool
 		expectedUpdates RepoUpdates
 		expectedError   bool
 		now             time.Time
@@ -270,7 +272,7 @@ func TestSyncLabels(t *testing.T) {
 			},
 			expectedUpdates: RepoUpdates{
 				"repo1": {
-					{Why: "change", Current: &Label{Name: "lab1", Description: "Test Label 1", Color: "deadbe"}, Wanted: &Label{Name: "lab1", Description: "Test Label 1", Color: "deadbe"}},
+					{Why: "change", Current: &Label{Name: "lab1", Description: "Test Label 1", Color: "bebeef"}, Wanted: &Label{Name: "lab1", Description: "Test Label 1", Color: "deadbe"}},
 				},
 			},
 		},
@@ -286,7 +288,7 @@ func TestSyncLabels(t *testing.T) {
 			},
 			expectedUpdates: RepoUpdates{
 				"repo1": {
-					{Why: "change", Current: &Label{Name: "lab1", Description: "Test Label 1", Color: "deadbe"}, Wanted: &Label{Name: "lab1", Description: "Test Label 1", Color: "deadbe"}},
+					{Why: "change", Current: &Label{Name: "lab1", Description: "Test Label 5", Color: "deadbe"}, Wanted: &Label{Name: "lab1", Description: "Test Label 1", Color: "deadbe"}},
 				},
 			},
 		},
@@ -364,6 +366,57 @@ func TestSyncLabels(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Unknown labels are kept by default",
+			config: Configuration{Default: RepoConfig{Labels: []Label{
+				{Name: "lab1", Description: "Test Label 1", Color: "deadbe"},
+			}}},
+			current: RepoLabels{
+				"repo1": {
+					{Name: "lab1", Description: "Test Label 1", Color: "deadbe"},
+					{Name: "stray", Description: "Stray", Color: "000000"},
+				},
+			},
+		},
+		{
+			name: "Unknown labels are deleted with deleteUnknown",
+			config: Configuration{Default: RepoConfig{Labels: []Label{
+				{Name: "lab1", Description: "Test Label 1", Color: "deadbe", Previously: []Label{
+					{Name: "old1", Description: "Old Label 1", Color: "deadbe"},
+				}},
+				{Name: "dead", Description: "Dead Label", Color: "cccccc", DeleteAfter: &time.Time{}},
+			}}},
+			current: RepoLabels{
+				"repo1": {
+					{Name: "lab1", Description: "Test Label 1", Color: "deadbe"},
+					{Name: "old1", Description: "Old Label 1", Color: "deadbe"},
+					{Name: "dead", Description: "Dead Label", Color: "cccccc"},
+					{Name: "Stray", Description: "Stray", Color: "000000"},
+				},
+			},
+			deleteUnknown: true,
+			expectedUpdates: RepoUpdates{
+				"repo1": {
+					{Why: "dead", Current: &Label{Name: "dead", Description: "Dead Label", Color: "cccccc"}},
+					{Why: "unknown", Current: &Label{Name: "Stray", Description: "Stray", Color: "000000"}},
+					{Why: "migrate", Current: &Label{Name: "old1", Description: "Old Label 1", Color: "deadbe"}, Wanted: &Label{Name: "lab1", Description: "Old Label 1", Color: "deadbe"}},
+				},
+			},
+		},
+		{
+			name: "Labels pending deletion are not unknown",
+			config: Configuration{Default: RepoConfig{Labels: []Label{
+				{Name: "lab1", Description: "Test Label 1", Color: "deadbe"},
+				{Name: "retiring", Description: "Retiring Label", Color: "cccccc", DeleteAfter: &future},
+			}}},
+			current: RepoLabels{
+				"repo1": {
+					{Name: "lab1", Description: "Test Label 1", Color: "deadbe"},
+					{Name: "Retiring", Description: "Retiring Label", Color: "cccccc"},
+				},
+			},
+			deleteUnknown: true,
+		},
 		{
 			name: "Multiple repos complex case",
 			config: Configuration{Default: RepoConfig{Labels: []Label{
@@ -396,11 +449,11 @@ func TestSyncLabels(t *testing.T) {
 					{Why: "rename", Wanted: &Label{Name: "lgtm", Description: "LGTM", Color: "00ff00"}, Current: &Label{Name: "LGTM", Description: "LGTM", Color: "00ff00"}},
 				},
 				"repo2": {
-					{Why: "change", Current: &Label{Name: "priority/P0", Description: "P0 Priority", Color: "ff0000"}, Wanted: &Label{Name: "priority/P0", Description: "P0 Priority", Color: "ff0000"}},
+					{Why: "change", Current: &Label{Name: "priority/P0", Description: "P0 Priority", Color: "ee3333"}, Wanted: &Label{Name: "priority/P0", Description: "P0 Priority", Color: "ff0000"}},
 				},
 				"repo3": {
 					{Why: "rename", Wanted: &Label{Name: "priority/P0", Description: "P0 Priority", Color: "ff0000"}, Current: &Label{Name: "PRIORITY/P0", Description: "P0 Priority", Color: "ff0000"}},
-					{Why: "change", Current: &Label{Name: "lgtm", Description: "LGTM", Color: "00ff00"}, Wanted: &Label{Name: "lgtm", Description: "LGTM", Color: "00ff00"}},
+					{Why: "change", Current: &Label{Name: "lgtm", Description: "LGTM", Color: "0000ff"}, Wanted: &Label{Name: "lgtm", Description: "LGTM", Color: "00ff00"}},
 				},
 				"repo4": {
 					{Why: "missing", Wanted: &Label{Name: "lgtm", Description: "LGTM", Color: "00ff00"}},
@@ -414,7 +467,7 @@ func TestSyncLabels(t *testing.T) {
 
 	// Do tests
 	for _, tc := range testcases {
-		actualUpdates, err := syncLabels(tc.config, "org", tc.current)
+		actualUpdates, err := syncLabels(tc.config, "org", tc.current, tc.deleteUnknown)
 		if err == nil && tc.expectedError {
 			t.Errorf("%s: failed to raise error", tc.name)
 		} else if err != nil && !tc.expectedError {
@@ -519,3 +572,57 @@ func TestLoadYAML(t *testing.T) {
 		}
 	}
 }
+
+// Test func (ru RepoUpdates) Diff(org string) string
+func TestDiff(t *testing.T) {
+	updates := RepoUpdates{
+		"repo2": {
+			{Why: "unknown", Current: &Label{Name: "stray"}},
+		},
+		"repo1": {
+			{Why: "missing", Wanted: &Label{Name: "lab1", Description: "Test Label 1", Color: "deadbe"}},
+			{Why: "change", Current: &Label{Name: "lab2", Description: "Old", Color: "bebeef"}, Wanted: &Label{Name: "lab2", Description: "New", Color: "deadbe"}},
+			{Why: "rename", Current: &Label{Name: "Lab3"}, Wanted: &Label{Name: "lab3", Description: "Test Label 3", Color: "deadbe"}},
+			{Why: "migrate", Current: &Label{Name: "old4"}, Wanted: &Label{Name: "lab4"}},
+			{Why: "dead", Current: &Label{Name: "lab5"}},
+		},
+	}
+	expected := `org/repo1: + create "lab1" (color deadbe, description "Test Label 1")
+org/repo1: ~ change "lab2": color bebeef -> deadbe, description "Old" -> "New"
+org/repo1: ~ rename "Lab3" -> "lab3" (color deadbe, description "Test Label 3")
+org/repo1: > migrate open issues and PRs from "old4" to "lab4"
+org/repo1: - delete "lab5" (past deleteAfter)
+org/repo2: - delete "stray" (not in labels.yaml)
+`
+	if diff := cmp.Diff(expected, updates.Diff("org")); diff != "" {
+		t.Errorf("TestDiff: diff differs from expected:%s", diff)
+	}
+}
+
+// Test func (c Configuration) orgNames() []string
+func TestOrgNames(t *testing.T) {
+	var testcases = []struct {
+		name     string
+		config   Configuration
+		expected []string
+	}{
+		{
+			name:     "Only default labels",
+			config:   Configuration{Default: RepoConfig{Labels: []Label{{Name: "lab1"}}}},
+			expected: []string{},
+		},
+		{
+			name: "Org and repo labels",
+			config: Configuration{
+				Orgs:  map[string]RepoConfig{"org2": {}, "org1": {}},
+				Repos: map[string]RepoConfig{"org1/repo1": {}, "org3/repo1": {}},
+			},
+			expected: []string{"org1", "org2", "org3"},
+		},
+	}
+	for _, tc := range testcases {
+		if diff := cmp.Diff(tc.expected, tc.config.orgNames()); diff != "" {
+			t.Errorf("%s: orgs differ:%s", tc.name, diff)
+		}
+	}
+}
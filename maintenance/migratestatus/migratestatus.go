@@ -39,6 +39,9 @@ type options struct {
 	github                                               prowflagutil.GitHubOptions
 	branchFilterRaw                                      string
 	branchFilter                                         *regexp.Regexp
+	retireRegexRaw                                       string
+	retireRegex                                          *regexp.Regexp
+	progressFile                                         string
 }
 
 func gatherOptions() options {
@@ -53,10 +56,12 @@ func gatherOptions() options {
 	fs.StringVar(&o.copyContext, "copy", "", "Indicates copy mode and specifies the context to copy.")
 	fs.StringVar(&o.moveContext, "move", "", "Indicates move mode and specifies the context to move.")
 	fs.StringVar(&o.retireContext, "retire", "", "Indicates retire mode and specifies the context to retire.")
+	fs.StringVar(&o.retireRegexRaw, "retire-regex", "", "Indicates retire mode and specifies a regular expression matching the contexts to retire.")
 	fs.StringVar(&o.destContext, "dest", "", "The destination context to copy or move to. For retire mode this is the context that replaced the retired context.")
 	fs.StringVar(&o.descriptionURL, "description", "", "A URL to a page explaining why a context was migrated or retired. (Optional)")
 
 	fs.StringVar(&o.branchFilterRaw, "branch-filter", "", "A regular expression which the PR target branch must match to be modified. (Optional)")
+	fs.StringVar(&o.progressFile, "progress-file", "", "A file to record migrated PRs in, so that an interrupted migration can be resumed. Ignored in dry-run mode. (Optional)")
 
 	o.github.AddFlags(fs)
 	fs.Parse(os.Args[1:])
@@ -71,8 +76,8 @@ func (o *options) Validate() error {
 		return errors.New("'--repo' must be set.\n")
 	}
 
-	if o.destContext == "" && o.retireContext == "" {
-		return errors.New("'--dest' is required unless using '--retire' mode.\n")
+	if o.destContext == "" && o.retireContext == "" && o.retireRegexRaw == "" {
+		return errors.New("'--dest' is required unless using '--retire' or '--retire-regex' mode.\n")
 	}
 
 	if o.descriptionURL != "" {
@@ -96,8 +101,11 @@ func (o *options) Validate() error {
 	if o.retireContext != "" {
 		optionCount++
 	}
+	if o.retireRegexRaw != "" {
+		optionCount++
+	}
 	if optionCount != 1 {
-		return errors.New("Exactly one mode must be specified [--copy|--retire|--move].")
+		return errors.New("Exactly one mode must be specified [--copy|--retire|--retire-regex|--move].")
 	}
 
 	if err := o.github.Validate(o.dryRun); err != nil {
@@ -110,6 +118,14 @@ func (o *options) Validate() error {
 	}
 	o.branchFilter = expr
 
+	if o.retireRegexRaw != "" {
+		expr, err := regexp.Compile(o.retireRegexRaw)
+		if err != nil {
+			return fmt.Errorf("invalid --retire-regex regular expression: %w", err)
+		}
+		o.retireRegex = expr
+	}
+
 	return nil
 }
 
@@ -136,10 +152,20 @@ func main() {
 	if o.retireContext != "" {
 		mode = migrator.RetireMode(o.retireContext, o.destContext, o.descriptionURL)
 	}
+	if o.retireRegex != nil {
+		mode = migrator.RetireRegexMode(o.retireRegex, o.destContext, o.descriptionURL)
+	}
 
 	// Note that continueOnError is false by default so that errors can be addressed when they occur
 	// instead of blindly continuing to the next PR, possibly continuing to error.
 	m := migrator.New(*mode, githubClient, o.org, o.repo, o.branchFilter.MatchString, o.continueOnError)
+	if o.progressFile != "" {
+		if o.dryRun {
+			logrus.Info("Not recording progress in dry-run mode.")
+		} else if err := m.ResumeFrom(o.progressFile); err != nil {
+			logrus.WithError(err).Fatal("Error loading migration progress")
+		}
+	}
 	if err := m.Migrate(); err != nil {
 		logrus.WithError(err).Fatal("Error during status migration")
 	}
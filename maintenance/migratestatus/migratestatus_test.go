@@ -64,3 +64,23 @@ func TestDescriptionDoesNotContainAURL(t *testing.T) {
 		t.Errorf("Error expected to contain parse error description, got %s", err.Error())
 	}
 }
+
+func TestRetireRegex(t *testing.T) {
+	o := options{org: "exampleOrg", repo: "exampleRepo", dryRun: true, retireRegexRaw: "^pull-.*-e2e$"}
+	if err := o.Validate(); err != nil {
+		t.Errorf("No error expected for a valid --retire-regex, got %v", err)
+	}
+	if o.retireRegex == nil || !o.retireRegex.MatchString("pull-test-infra-e2e") {
+		t.Errorf("Expected --retire-regex to be compiled, got %v", o.retireRegex)
+	}
+
+	o = options{org: "exampleOrg", repo: "exampleRepo", dryRun: true, retireRegexRaw: "("}
+	if err := o.Validate(); err == nil {
+		t.Error("Error expected for an invalid --retire-regex, got nil")
+	}
+
+	o = options{org: "exampleOrg", repo: "exampleRepo", dryRun: true, retireRegexRaw: ".*", retireContext: "retireContext"}
+	if err := o.Validate(); err == nil {
+		t.Error("Error expected for both --retire and --retire-regex, got nil")
+	}
+}
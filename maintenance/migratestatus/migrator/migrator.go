@@ -17,7 +17,12 @@ limitations under the License.
 package migrator
 
 import (
+	"bufio"
 	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/golang/glog"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -34,11 +39,22 @@ var (
 type contextCondition struct {
 	// context is the status context that this condition applies to.
 	context string
+	// contextRE, if set, is used instead of context and the condition applies to the first status
+	// context that it matches.
+	contextRE *regexp.Regexp
 	// state is the status state that the condition accepts, or one of the special values "ANY_STATE"
 	// and "DOES_NOT_EXIST".
 	state string
 }
 
+// matches returns true if the condition applies to the status context.
+func (c *contextCondition) matches(context string) bool {
+	if c.contextRE != nil {
+		return c.contextRE.MatchString(context)
+	}
+	return context == c.context
+}
+
 // Mode is a struct that describes the behavior of a status migration. The behavior is described as
 // a list of conditions and a function that determines the actions to be taken when the conditions
 // are met.
@@ -98,6 +114,33 @@ func RetireMode(origContext, newContext, targetURL string) *Mode {
 	}
 }
 
+// RetireRegexMode creates a mode that retires every context matching contextRE on all PRs.
+// It behaves like RetireMode for each matching context, except that newContext is never retired
+// even if it matches and that contexts which are already retired are left untouched.
+func RetireRegexMode(contextRE *regexp.Regexp, newContext, targetURL string) *Mode {
+	conditions := []*contextCondition{{contextRE: contextRE, state: stateAny}}
+	if newContext != "" {
+		conditions = append(conditions, &contextCondition{context: newContext, state: stateAny})
+	}
+	return &Mode{
+		conditions: conditions,
+		actions: func(statuses []github.Status, sha string) []github.Status {
+			var actions []github.Status
+			for _, status := range statuses {
+				if status.Context == newContext || !contextRE.MatchString(status.Context) {
+					continue
+				}
+				retire := retireAction(status.Context, newContext, targetURL)(statuses, sha)
+				if status.State == retire[0].State && status.Description == retire[0].Description {
+					continue
+				}
+				actions = append(actions, retire...)
+			}
+			return actions
+		},
+	}
+}
+
 // copyAction creates a function that returns a copy action.
 // Specifically the returned function returns a RepoStatus that will create a status for newContext
 // with state set to the state of origContext.
@@ -163,7 +206,7 @@ func (m Mode) processStatuses(combStatus *github.CombinedStatus) []github.Status
 				glog.Errorf("a status context for SHA ref '%s' had an empty Context field.", combStatus.SHA)
 				continue
 			}
-			if status.Context == cond.context {
+			if cond.matches(status.Context) {
 				match = status
 				found = true
 				break
@@ -186,7 +229,7 @@ func (m Mode) processStatuses(combStatus *github.CombinedStatus) []github.Status
 				return nil
 			}
 			if match.State == "" {
-				glog.Errorf("context '%s' of SHA ref '%s' has an empty state.", cond.context, combStatus.SHA)
+				glog.Errorf("context '%s' of SHA ref '%s' has an empty state.", match.Context, combStatus.SHA)
 				return nil
 			}
 			if match.State != cond.state {
@@ -213,6 +256,12 @@ type Migrator struct {
 
 	continueOnError bool
 
+	// progressPath is the file that migrated PR numbers and head SHAs are appended to, if set.
+	progressPath string
+	// done maps the PRs that were already migrated according to progressPath to the
+	// head SHA they were migrated at.
+	done map[int]string
+
 	client githubClient
 	Mode
 }
@@ -229,6 +278,59 @@ func New(mode Mode, client github.Client, org, repo string, targetBranchFilter f
 	}
 }
 
+// ResumeFrom makes the migrator record every PR that it finishes migrating in the file at path,
+// and skip the PRs that are already recorded there, unless they were pushed to since. This allows
+// an interrupted migration to be resumed without reprocessing every PR. The file is created if it
+// does not exist.
+func (m *Migrator) ResumeFrom(path string) error {
+	m.progressPath = path
+	m.done = map[int]string{}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open progress file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		// Lines without a SHA never match a head, so those PRs are migrated again.
+		fields := strings.Fields(line)
+		number, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid PR number %q in progress file: %w", line, err)
+		}
+		m.done[number] = ""
+		if len(fields) > 1 {
+			m.done[number] = fields[1]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read progress file: %w", err)
+	}
+	glog.Infof("Resuming migration, skipping %d PRs recorded in %s.", len(m.done), path)
+	return nil
+}
+
+// recordProgress appends the PR number and the head SHA it was migrated at to the progress file.
+func (m *Migrator) recordProgress(number int, sha string) error {
+	f, err := os.OpenFile(m.progressPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open progress file: %w", err)
+	}
+	if _, err := fmt.Fprintln(f, number, sha); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to record progress for PR #%d: %w", number, err)
+	}
+	return f.Close()
+}
+
 func (m *Migrator) processPR(pr github.PullRequest) error {
 	if !m.targetBranchFilter(pr.Base.Ref) {
 		return nil
@@ -257,6 +359,9 @@ func (m *Migrator) Migrate() error {
 
 	var errors []error
 	for _, pr := range prs {
+		if sha, ok := m.done[pr.Number]; ok && sha == pr.Head.SHA {
+			continue
+		}
 		if err := m.processPR(pr); err != nil {
 			if m.continueOnError {
 				errors = append(errors, err)
@@ -264,6 +369,11 @@ func (m *Migrator) Migrate() error {
 			}
 			return err
 		}
+		if m.progressPath != "" {
+			if err := m.recordProgress(pr.Number, pr.Head.SHA); err != nil {
+				return err
+			}
+		}
 	}
 	return utilerrors.NewAggregate(errors)
 }
@@ -18,6 +18,11 @@ package migrator
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
 	"testing"
 
 	"github.com/pkg/errors"
@@ -357,6 +362,71 @@ func TestRetireModeNoReplacement(t *testing.T) {
 	}
 }
 
+func TestRetireRegexMode(t *testing.T) {
+	contextB := "context B"
+	desc := "Context retired. Status moved to \"context B\"."
+
+	tests := []*modeTest{
+		{
+			name: "all matching contexts",
+			start: []github.Status{
+				makeStatus("context A1", "failure", "description 1", "url 1"),
+				makeStatus("context A2", "pending", "description 2", "url 2"),
+				makeStatus("unrelated context", "failure", "description 3", "url 3"),
+				makeStatus(contextB, "failure", "description 4", "url 4"),
+			},
+			expectedDiffs: []github.Status{
+				makeStatus("context A1", "success", desc, ""),
+				makeStatus("context A2", "success", desc, ""),
+			},
+		},
+		{
+			name: "already retired",
+			start: []github.Status{
+				makeStatus("context A1", "success", desc, ""),
+				makeStatus("context A2", "failure", "description 2", "url 2"),
+				makeStatus(contextB, "failure", "description 4", "url 4"),
+			},
+			expectedDiffs: []github.Status{
+				makeStatus("context A2", "success", desc, ""),
+			},
+		},
+		{
+			name: "missing context B",
+			start: []github.Status{
+				makeStatus("context A1", "failure", "description 1", "url 1"),
+			},
+			expectedDiffs: []github.Status{},
+		},
+		{
+			name: "no matching contexts",
+			start: []github.Status{
+				makeStatus("unrelated context", "failure", "description 3", "url 3"),
+				makeStatus(contextB, "failure", "description 4", "url 4"),
+			},
+			expectedDiffs: []github.Status{},
+		},
+	}
+
+	m := *RetireRegexMode(regexp.MustCompile("^context A"), contextB, "")
+	for _, test := range tests {
+		diff := m.processStatuses(&github.CombinedStatus{Statuses: test.start})
+		if err := compareDiffs(diff, test.expectedDiffs); err != nil {
+			t.Errorf("RetireRegexMode test '%s' %v\n", test.name, err)
+		}
+	}
+
+	// The replacement is never retired, even if it matches.
+	m = *RetireRegexMode(regexp.MustCompile("^context"), contextB, "")
+	diff := m.processStatuses(&github.CombinedStatus{Statuses: []github.Status{
+		makeStatus("context A1", "failure", "description 1", "url 1"),
+		makeStatus(contextB, "failure", "description 4", "url 4"),
+	}})
+	if err := compareDiffs(diff, []github.Status{makeStatus("context A1", "success", desc, "")}); err != nil {
+		t.Errorf("RetireRegexMode test 'matching replacement' %v\n", err)
+	}
+}
+
 // makeStatus returns a new Status struct with the specified fields.
 // targetURL=="" means TargetURL==nil
 func makeStatus(context, state, description, targetURL string) github.Status {
@@ -434,3 +504,80 @@ func TestProcessPR(t *testing.T) {
 		}
 	}
 }
+
+type fakeMigrationClient struct {
+	prs     []github.PullRequest
+	created []string
+}
+
+func (c *fakeMigrationClient) GetCombinedStatus(org, repo, ref string) (*github.CombinedStatus, error) {
+	return &github.CombinedStatus{SHA: ref, Statuses: []github.Status{makeStatus("context A", "failure", "description", "")}}, nil
+}
+
+func (c *fakeMigrationClient) CreateStatus(org, repo, SHA string, s github.Status) error {
+	c.created = append(c.created, SHA)
+	return nil
+}
+
+func (c *fakeMigrationClient) GetPullRequests(org, repo string) ([]github.PullRequest, error) {
+	return c.prs, nil
+}
+
+func TestMigrateResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migratestatus")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "progress")
+	if err := ioutil.WriteFile(path, []byte("1 sha1\n3 old\n4\n5 sha5\n"), 0644); err != nil {
+		t.Fatalf("failed to write progress file: %v", err)
+	}
+
+	client := &fakeMigrationClient{}
+	for i := 1; i <= 5; i++ {
+		client.prs = append(client.prs, github.PullRequest{Number: i, Head: github.PullRequestBranch{SHA: fmt.Sprintf("sha%d", i)}})
+	}
+	migrator := Migrator{
+		org:                "org",
+		repo:               "repo",
+		targetBranchFilter: func(string) bool { return true },
+		client:             client,
+		Mode:               *RetireMode("context A", "", ""),
+	}
+	if err := migrator.ResumeFrom(path); err != nil {
+		t.Fatalf("ResumeFrom failed: %v", err)
+	}
+	if err := migrator.Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	// PR 3 was pushed to and PR 4 was recorded without a SHA since.
+	if expected := []string{"sha2", "sha3", "sha4"}; !reflect.DeepEqual(client.created, expected) {
+		t.Errorf("expected statuses to be created for %v, but got %v", expected, client.created)
+	}
+	progress, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read progress file: %v", err)
+	}
+	if expected := "1 sha1\n3 old\n4\n5 sha5\n2 sha2\n3 sha3\n4 sha4\n"; string(progress) != expected {
+		t.Errorf("expected progress file %q, but got %q", expected, string(progress))
+	}
+}
+
+func TestResumeFromInvalidProgress(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migratestatus")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "progress")
+	if err := ioutil.WriteFile(path, []byte("1\nnot a number\n"), 0644); err != nil {
+		t.Fatalf("failed to write progress file: %v", err)
+	}
+
+	var migrator Migrator
+	if err := migrator.ResumeFrom(path); err == nil {
+		t.Error("expected an error for an invalid progress file")
+	}
+}
@@ -32,6 +32,8 @@ import (
 
 type issueService interface {
 	Create(ctx context.Context, owner string, repo string, issue *github.IssueRequest) (*github.Issue, *github.Response, error)
+	CreateComment(ctx context.Context, owner string, repo string, number int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error)
+	Edit(ctx context.Context, owner string, repo string, number int, issue *github.IssueRequest) (*github.Issue, *github.Response, error)
 	ListByRepo(ctx context.Context, org, repo string, opt *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error)
 	ListLabels(ctx context.Context, owner, repo string, opt *github.ListOptions) ([]*github.Label, *github.Response, error)
 }
@@ -81,6 +83,41 @@ func (c *Client) CreateIssue(org, repo, title, body string, labels, assignees []
 	return result, err
 }
 
+// CloseIssue tries to comment on and then close a github issue, returning the closed issue.
+// No comment is made if comment is "".
+func (c *Client) CloseIssue(org, repo string, number int, comment string) (*github.Issue, error) {
+	glog.Infof("CloseIssue(dry=%t) Number:%d, Comment:%q\n", c.dryRun, number, comment)
+	if c.dryRun {
+		return nil, nil
+	}
+
+	if comment != "" {
+		_, err := c.retry(
+			fmt.Sprintf("commenting on issue #%d", number),
+			func() (*github.Response, error) {
+				_, resp, err := c.issueService.CreateComment(context.Background(), org, repo, number, &github.IssueComment{Body: &comment})
+				return resp, err
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	state := "closed"
+	var result *github.Issue
+	_, err := c.retry(
+		fmt.Sprintf("closing issue #%d", number),
+		func() (*github.Response, error) {
+			var resp *github.Response
+			var err error
+			result, resp, err = c.issueService.Edit(context.Background(), org, repo, number, &github.IssueRequest{State: &state})
+			return resp, err
+		},
+	)
+	return result, err
+}
+
 // CreateStatus creates or updates a status context on the indicated reference.
 func (c *Client) CreateStatus(owner, repo, ref string, status *github.RepoStatus) (*github.RepoStatus, error) {
 	glog.Infof("CreateStatus(dry=%t) ref:%s: %s:%s", c.dryRun, ref, *status.Context, *status.State)
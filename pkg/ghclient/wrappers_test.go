@@ -321,6 +321,41 @@ func (f *fakeIssueService) Create(ctx context.Context, owner string, repo string
 	return result, resp, nil
 }
 
+func (f *fakeIssueService) CreateComment(ctx context.Context, owner string, repo string, number int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error) {
+	resp := &github.Response{Rate: github.Rate{Limit: 5000, Remaining: 1000, Reset: github.Timestamp{Time: time.Now()}}}
+	if owner != f.org {
+		return nil, resp, fmt.Errorf("org '%s' not recognized, only '%s' is valid", owner, f.org)
+	}
+	if repo != f.repo {
+		return nil, resp, fmt.Errorf("repo '%s' not recognized, only '%s' is valid", repo, f.repo)
+	}
+	issue, ok := f.repoIssues[number]
+	if !ok {
+		return nil, resp, fmt.Errorf("issue #%d does not exist", number)
+	}
+	comments := issue.GetComments() + 1
+	issue.Comments = &comments
+	return comment, resp, nil
+}
+
+func (f *fakeIssueService) Edit(ctx context.Context, owner string, repo string, number int, issue *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	resp := &github.Response{Rate: github.Rate{Limit: 5000, Remaining: 1000, Reset: github.Timestamp{Time: time.Now()}}}
+	if owner != f.org {
+		return nil, resp, fmt.Errorf("org '%s' not recognized, only '%s' is valid", owner, f.org)
+	}
+	if repo != f.repo {
+		return nil, resp, fmt.Errorf("repo '%s' not recognized, only '%s' is valid", repo, f.repo)
+	}
+	result, ok := f.repoIssues[number]
+	if !ok {
+		return nil, resp, fmt.Errorf("issue #%d does not exist", number)
+	}
+	if issue.State != nil {
+		result.State = issue.State
+	}
+	return result, resp, nil
+}
+
 // ListByRepo returns 2 issues per page of results (served in order by number).
 func (f *fakeIssueService) ListByRepo(ctx context.Context, org, repo string, opt *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error) {
 	resp := &github.Response{
@@ -408,6 +443,38 @@ func TestCreateIssue(t *testing.T) {
 	}
 }
 
+func TestCloseIssue(t *testing.T) {
+	svc := newFakeIssueService("k8s", "kuber", nil, 3)
+	client := &Client{issueService: svc}
+	setForTest(client)
+	issue, err := client.CloseIssue("k8s", "kuber", 2, "Recovered")
+	if err != nil {
+		t.Fatalf("Unexpected error from CloseIssue with valid args: %v.", err)
+	}
+	if issue == nil {
+		t.Fatalf("Expected issue returned by CloseIssue to be non-nil, but it was nil.")
+	}
+	if issue.State == nil || *issue.State != "closed" {
+		t.Errorf("Expected issue from CloseIssue to have a state of 'closed' instead of '%s'.", issue.GetState())
+	}
+	if comments := issue.GetComments(); comments != 1 {
+		t.Errorf("Expected CloseIssue to leave 1 comment instead of %d.", comments)
+	}
+
+	// test no comment
+	issue, err = client.CloseIssue("k8s", "kuber", 3, "")
+	if err != nil {
+		t.Fatalf("Unexpected error from CloseIssue with valid args: %v.", err)
+	}
+	if comments := issue.GetComments(); comments != 0 {
+		t.Errorf("Expected CloseIssue without a comment to leave no comments instead of %d.", comments)
+	}
+
+	if _, err = client.CloseIssue("k8s", "kuber", 4, ""); err == nil {
+		t.Error("Expected error from CloseIssue on invalid issue, but didn't get an error.")
+	}
+}
+
 func TestGetIssues(t *testing.T) {
 	var issues []*github.Issue
 	var err error
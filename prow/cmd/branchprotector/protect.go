@@ -476,10 +476,12 @@ func (p *protector) UpdateBranch(orgName, repo string, branchName string, branch
 		return fmt.Errorf("get current branch protection: %w", err)
 	}
 
-	if equalBranchProtections(currentBP, req) {
+	changes := protectionDiff(currentBP, req)
+	if len(changes) == 0 {
 		logrus.Debugf("%s/%s=%s: current branch protection matches policy, skipping", orgName, repo, branchName)
 		return nil
 	}
+	logrus.WithField("changes", changes).Infof("%s/%s=%s: branch protection differs from policy", orgName, repo, branchName)
 
 	p.updates <- requirements{
 		Org:     orgName,
@@ -491,20 +493,43 @@ func (p *protector) UpdateBranch(orgName, repo string, branchName string, branch
 }
 
 func equalBranchProtections(state *github.BranchProtection, request *github.BranchProtectionRequest) bool {
+	return len(protectionDiff(state, request)) == 0
+}
+
+// protectionDiff lists the policy fields where the current state differs from the request,
+// so that a dry run shows what the update would change.
+func protectionDiff(state *github.BranchProtection, request *github.BranchProtectionRequest) []string {
 	switch {
 	case state == nil && request == nil:
-		return true
-	case state != nil && request != nil:
-		return equalRequiredStatusChecks(state.RequiredStatusChecks, request.RequiredStatusChecks) &&
-			equalAdminEnforcement(state.EnforceAdmins, request.EnforceAdmins) &&
-			equalRequiredPullRequestReviews(state.RequiredPullRequestReviews, request.RequiredPullRequestReviews) &&
-			equalRestrictions(state.Restrictions, request.Restrictions) &&
-			equalAllowForcePushes(state.AllowForcePushes, request.AllowForcePushes) &&
-			equalRequiredLinearHistory(state.RequiredLinearHistory, request.RequiredLinearHistory) &&
-			equalAllowDeletions(state.AllowDeletions, request.AllowDeletions)
-	default:
-		return false
+		return nil
+	case state == nil:
+		return []string{"protect"}
+	case request == nil:
+		return []string{"unprotect"}
+	}
+	var changes []string
+	if !equalRequiredStatusChecks(state.RequiredStatusChecks, request.RequiredStatusChecks) {
+		changes = append(changes, "required_status_checks")
+	}
+	if !equalAdminEnforcement(state.EnforceAdmins, request.EnforceAdmins) {
+		changes = append(changes, "enforce_admins")
+	}
+	if !equalRequiredPullRequestReviews(state.RequiredPullRequestReviews, request.RequiredPullRequestReviews) {
+		changes = append(changes, "required_pull_request_reviews")
+	}
+	if !equalRestrictions(state.Restrictions, request.Restrictions) {
+		changes = append(changes, "restrictions")
+	}
+	if !equalAllowForcePushes(state.AllowForcePushes, request.AllowForcePushes) {
+		changes = append(changes, "allow_force_pushes")
+	}
+	if !equalRequiredLinearHistory(state.RequiredLinearHistory, request.RequiredLinearHistory) {
+		changes = append(changes, "required_linear_history")
+	}
+	if !equalAllowDeletions(state.AllowDeletions, request.AllowDeletions) {
+		changes = append(changes, "allow_deletions")
 	}
+	return changes
 }
 
 func equalRequiredStatusChecks(state, request *github.RequiredStatusChecks) bool {
@@ -1638,6 +1638,58 @@ func TestEqualBranchProtection(t *testing.T) {
 	}
 }
 
+func TestProtectionDiff(t *testing.T) {
+	yes := true
+	var testCases = []struct {
+		name     string
+		state    *github.BranchProtection
+		request  *github.BranchProtectionRequest
+		expected []string
+	}{
+		{
+			name: "neither set has no changes",
+		},
+		{
+			name:     "state unset protects",
+			request:  &github.BranchProtectionRequest{},
+			expected: []string{"protect"},
+		},
+		{
+			name:     "request unset unprotects",
+			state:    &github.BranchProtection{},
+			expected: []string{"unprotect"},
+		},
+		{
+			name: "differing fields are listed",
+			state: &github.BranchProtection{
+				RequiredStatusChecks: &github.RequiredStatusChecks{
+					Contexts: []string{"a"},
+				},
+				RequiredPullRequestReviews: &github.RequiredPullRequestReviews{
+					RequiredApprovingReviewCount: 1,
+				},
+			},
+			request: &github.BranchProtectionRequest{
+				RequiredStatusChecks: &github.RequiredStatusChecks{
+					Contexts: []string{"a", "b"},
+				},
+				EnforceAdmins: &yes,
+				RequiredPullRequestReviews: &github.RequiredPullRequestReviewsRequest{
+					RequiredApprovingReviewCount: 1,
+				},
+				AllowDeletions: true,
+			},
+			expected: []string{"required_status_checks", "enforce_admins", "allow_deletions"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		if diff := cmp.Diff(testCase.expected, protectionDiff(testCase.state, testCase.request)); diff != "" {
+			t.Errorf("%s: incorrect changes: %s", testCase.name, diff)
+		}
+	}
+}
+
 func TestEqualStatusChecks(t *testing.T) {
 	var testCases = []struct {
 		name     string
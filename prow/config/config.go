@@ -1288,6 +1288,20 @@ type ManagedWebhooks struct {
 type SlackReporter struct {
 	JobTypesToReport            []prowapi.ProwJobType `json:"job_types_to_report,omitempty"`
 	prowapi.SlackReporterConfig `json:",inline"`
+
+	// JobNameRegexp restricts reporting to jobs whose name matches this
+	// regular expression. All jobs are reported if unset.
+	JobNameRegexp string `json:"job_name_regexp,omitempty"`
+	// JobNameRe is the compiled version of JobNameRegexp. It should not be
+	// specified in config.
+	JobNameRe *regexp.Regexp `json:"-"`
+	// Branches restricts reporting to jobs running against one of these
+	// base branches. Jobs without refs are not reported if this is set.
+	Branches []string `json:"branches,omitempty"`
+	// MinReportInterval is the minimum time between two reports for the
+	// same job to the same channel. Reports that arrive sooner are dropped,
+	// so a flaky job does not flood the channel. Unset means no throttling.
+	MinReportInterval *metav1.Duration `json:"min_report_interval,omitempty"`
 }
 
 // SlackReporterConfigs represents the config for the Slack reporter(s).
@@ -1320,6 +1334,18 @@ func (cfg *SlackReporter) DefaultAndValidate() error {
 		return errors.New("channel must be set")
 	}
 
+	if cfg.JobNameRegexp != "" {
+		re, err := regexp.Compile(cfg.JobNameRegexp)
+		if err != nil {
+			return fmt.Errorf("failed to compile job_name_regexp: %w", err)
+		}
+		cfg.JobNameRe = re
+	}
+
+	if cfg.MinReportInterval != nil && cfg.MinReportInterval.Duration < 0 {
+		return fmt.Errorf("min_report_interval must not be negative, was %s", cfg.MinReportInterval.Duration)
+	}
+
 	// Validate ReportTemplate
 	tmpl, err := template.New("").Parse(cfg.ReportTemplate)
 	if err != nil {
@@ -4231,6 +4231,64 @@ func TestSlackReporterValidation(t *testing.T) {
 			},
 			successExpected: false,
 		},
+		{
+			name: "Valid job_name_regexp and min_report_interval - no error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						SlackReporterConfig: prowjobv1.SlackReporterConfig{
+							Channel: "my-channel",
+						},
+						JobNameRegexp:     "^ci-kubernetes-",
+						MinReportInterval: &metav1.Duration{Duration: time.Hour},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: true,
+		},
+		{
+			name: "Invalid job_name_regexp - error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						SlackReporterConfig: prowjobv1.SlackReporterConfig{
+							Channel: "my-channel",
+						},
+						JobNameRegexp: "ci-kubernetes-(",
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: false,
+		},
+		{
+			name: "Negative min_report_interval - error",
+			config: func() Config {
+				slackCfg := map[string]SlackReporter{
+					"*": {
+						SlackReporterConfig: prowjobv1.SlackReporterConfig{
+							Channel: "my-channel",
+						},
+						MinReportInterval: &metav1.Duration{Duration: -time.Minute},
+					},
+				}
+				return Config{
+					ProwConfig: ProwConfig{
+						SlackReporterConfigs: slackCfg,
+					},
+				}
+			},
+			successExpected: false,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -4247,6 +4305,9 @@ func TestSlackReporterValidation(t *testing.T) {
 					if config.Channel == "" {
 						t.Errorf("expected Channel to be required")
 					}
+					if config.JobNameRegexp != "" && (config.JobNameRe == nil || config.JobNameRe.String() != config.JobNameRegexp) {
+						t.Errorf("expected JobNameRe to be compiled from %q, got %v", config.JobNameRegexp, config.JobNameRe)
+					}
 				}
 			}
 		})
@@ -21,9 +21,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"text/template"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
@@ -45,6 +49,24 @@ type slackReporter struct {
 	clients map[string]slackClient
 	config  func(*prowapi.Refs) config.SlackReporter
 	dryRun  bool
+	clock   clock.Clock
+
+	// lastReportsLock guards lastReports and lastPrune.
+	lastReportsLock sync.Mutex
+	// lastReports holds the most recent report per host, channel and
+	// job name and is used to deduplicate and throttle reports.
+	lastReports map[string]lastReport
+	// lastPrune is when lastReports was last pruned.
+	lastPrune time.Time
+}
+
+// lastReport records a message that was written to Slack.
+type lastReport struct {
+	prowJob string
+	state   v1.ProwJobState
+	time    time.Time
+	// minInterval is the throttling interval the report was written with.
+	minInterval time.Duration
 }
 
 func hostAndChannel(cfg *v1.SlackReporterConfig) (string, string) {
@@ -72,7 +94,7 @@ func (sr *slackReporter) Report(_ context.Context, log *logrus.Entry, pj *v1.Pro
 	return []*v1.ProwJob{pj}, nil, sr.report(log, pj)
 }
 
-func (sr *slackReporter) report(log *logrus.Entry, pj *v1.ProwJob) error {
+func (sr *slackReporter) report(log *logrus.Entry, pj *v1.ProwJob) (err error) {
 	globalSlackConfig, jobSlackConfig := sr.getConfig(pj)
 	if globalSlackConfig != nil {
 		jobSlackConfig = jobSlackConfig.ApplyDefault(&globalSlackConfig.SlackReporterConfig)
@@ -86,6 +108,17 @@ func (sr *slackReporter) report(log *logrus.Entry, pj *v1.ProwJob) error {
 	if !ok {
 		return fmt.Errorf("host '%s' not supported", host)
 	}
+	skip, reason, rollback := sr.deduplicateOrThrottle(host, channel, pj, globalSlackConfig.MinReportInterval)
+	if skip {
+		log.WithFields(logrus.Fields{"host": host, "channel": channel}).Debugf("Skipping report: %s", reason)
+		return nil
+	}
+	// Forget about the report if it fails, so that the retry is not deduplicated.
+	defer func() {
+		if err != nil {
+			rollback()
+		}
+	}()
 	b := &bytes.Buffer{}
 	tmpl, err := template.New("").Parse(jobSlackConfig.ReportTemplate)
 	if err != nil {
@@ -107,6 +140,78 @@ func (sr *slackReporter) report(log *logrus.Entry, pj *v1.ProwJob) error {
 	return nil
 }
 
+// deduplicateOrThrottle determines whether a report for the job to the given
+// channel must be dropped because the very same job run was already reported
+// in this state or because the job was reported less than minInterval ago.
+// If the report is not dropped, it is recorded as the latest one and the
+// returned func restores the previous record if the report fails after all.
+func (sr *slackReporter) deduplicateOrThrottle(host, channel string, pj *v1.ProwJob, minInterval *metav1.Duration) (bool, string, func()) {
+	sr.lastReportsLock.Lock()
+	defer sr.lastReportsLock.Unlock()
+	if sr.lastReports == nil {
+		sr.lastReports = map[string]lastReport{}
+	}
+	now := sr.now()
+	sr.pruneLastReports(now)
+	key := fmt.Sprintf("%s/%s/%s", host, channel, pj.Spec.Job)
+	last, ok := sr.lastReports[key]
+	if ok {
+		if last.prowJob == pj.Name && last.state == pj.Status.State {
+			return true, fmt.Sprintf("prowjob %s was already reported with state %s", pj.Name, pj.Status.State), nil
+		}
+		if minInterval != nil && now.Sub(last.time) < minInterval.Duration {
+			return true, fmt.Sprintf("job %s was last reported at %s, less than %s ago", pj.Spec.Job, last.time, minInterval.Duration), nil
+		}
+	}
+	report := lastReport{prowJob: pj.Name, state: pj.Status.State, time: now}
+	if minInterval != nil {
+		report.minInterval = minInterval.Duration
+	}
+	sr.lastReports[key] = report
+	return false, "", func() {
+		sr.lastReportsLock.Lock()
+		defer sr.lastReportsLock.Unlock()
+		// Leave newer reports alone.
+		if sr.lastReports[key] != report {
+			return
+		}
+		if ok {
+			sr.lastReports[key] = last
+		} else {
+			delete(sr.lastReports, key)
+		}
+	}
+}
+
+// lastReportsTTL is how long reports are remembered for deduplication if the
+// throttling interval does not require remembering them longer.
+const lastReportsTTL = 24 * time.Hour
+
+// pruneLastReports forgets reports that can no longer cause a report to be
+// dropped. The caller must hold lastReportsLock.
+func (sr *slackReporter) pruneLastReports(now time.Time) {
+	if now.Sub(sr.lastPrune) < time.Hour {
+		return
+	}
+	sr.lastPrune = now
+	for key, last := range sr.lastReports {
+		ttl := lastReportsTTL
+		if last.minInterval > ttl {
+			ttl = last.minInterval
+		}
+		if now.Sub(last.time) >= ttl {
+			delete(sr.lastReports, key)
+		}
+	}
+}
+
+func (sr *slackReporter) now() time.Time {
+	if sr.clock == nil {
+		return time.Now()
+	}
+	return sr.clock.Now()
+}
+
 func (sr *slackReporter) GetName() string {
 	return reporterName
 }
@@ -149,11 +254,35 @@ func (sr *slackReporter) ShouldReport(_ context.Context, logger *logrus.Entry, p
 		}
 	}
 
-	shouldReport := stateShouldReport && (typeShouldReport || jobShouldReport)
+	shouldReport := stateShouldReport && (typeShouldReport || jobShouldReport) && matchesFilters(globalSlackConfig, pj)
 	logger.WithField("reporting", shouldReport).Debug("Determined should report")
 	return shouldReport
 }
 
+// matchesFilters determines whether the job matches the job name and branch
+// filters of the Slack reporter config.
+func matchesFilters(cfg *config.SlackReporter, pj *v1.ProwJob) bool {
+	if cfg.JobNameRe != nil && !cfg.JobNameRe.MatchString(pj.Spec.Job) {
+		return false
+	}
+	if len(cfg.Branches) == 0 {
+		return true
+	}
+	refs := pj.Spec.Refs
+	if refs == nil && len(pj.Spec.ExtraRefs) > 0 {
+		refs = &pj.Spec.ExtraRefs[0]
+	}
+	if refs == nil {
+		return false
+	}
+	for _, branch := range cfg.Branches {
+		if branch == refs.BaseRef {
+			return true
+		}
+	}
+	return false
+}
+
 func New(cfg func(refs *prowapi.Refs) config.SlackReporter, dryRun bool, tokensMap map[string]func() []byte) *slackReporter {
 	clients := map[string]slackClient{}
 	for key, val := range tokensMap {
@@ -163,5 +292,6 @@ func New(cfg func(refs *prowapi.Refs) config.SlackReporter, dryRun bool, tokensM
 		clients: clients,
 		config:  cfg,
 		dryRun:  dryRun,
+		clock:   clock.RealClock{},
 	}
 }
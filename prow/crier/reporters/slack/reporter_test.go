@@ -18,9 +18,16 @@ package slack
 
 import (
 	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
 
 	v1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
 	"k8s.io/test-infra/prow/config"
@@ -280,6 +287,105 @@ func TestShouldReport(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name: "Job matching job name regexp should report",
+			config: config.SlackReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.PeriodicJob},
+				SlackReporterConfig: v1.SlackReporterConfig{
+					JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				},
+				JobNameRe: regexp.MustCompile(`^ci-kubernetes-`),
+			},
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Type: v1.PeriodicJob,
+					Job:  "ci-kubernetes-e2e",
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.FailureState,
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "Job not matching job name regexp should not report",
+			config: config.SlackReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.PeriodicJob},
+				SlackReporterConfig: v1.SlackReporterConfig{
+					JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				},
+				JobNameRe: regexp.MustCompile(`^ci-kubernetes-`),
+			},
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Type: v1.PeriodicJob,
+					Job:  "ci-test-infra-verify",
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.FailureState,
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "Job against configured branch should report",
+			config: config.SlackReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.PostsubmitJob},
+				SlackReporterConfig: v1.SlackReporterConfig{
+					JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				},
+				Branches: []string{"master", "release-1.20"},
+			},
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Type: v1.PostsubmitJob,
+					Refs: &v1.Refs{BaseRef: "release-1.20"},
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.FailureState,
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "Job against other branch should not report",
+			config: config.SlackReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.PostsubmitJob},
+				SlackReporterConfig: v1.SlackReporterConfig{
+					JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				},
+				Branches: []string{"master"},
+			},
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Type: v1.PostsubmitJob,
+					Refs: &v1.Refs{BaseRef: "feature"},
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.FailureState,
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "Job without refs should not report when branches are configured",
+			config: config.SlackReporter{
+				JobTypesToReport: []v1.ProwJobType{v1.PeriodicJob},
+				SlackReporterConfig: v1.SlackReporterConfig{
+					JobStatesToReport: []v1.ProwJobState{v1.FailureState},
+				},
+				Branches: []string{"master"},
+			},
+			pj: &v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Type: v1.PeriodicJob,
+				},
+				Status: v1.ProwJobStatus{
+					State: v1.FailureState,
+				},
+			},
+			expected: false,
+		},
 		{
 			name:   "Empty config should not report",
 			config: config.SlackReporter{},
@@ -622,9 +728,13 @@ func TestShouldReportDefaultsToExtraRefs(t *testing.T) {
 
 type fakeSlackClient struct {
 	messages map[string]string
+	err      error
 }
 
 func (fsc *fakeSlackClient) WriteMessage(text, channel string) error {
+	if fsc.err != nil {
+		return fsc.err
+	}
 	if fsc.messages == nil {
 		fsc.messages = map[string]string{}
 	}
@@ -669,3 +779,132 @@ func TestReportDefaultsToExtraRefs(t *testing.T) {
 		t.Errorf("expected the channel 'emergency' to contain message 'there you go' but wasn't the case, all messages: %v", fsc.messages)
 	}
 }
+
+func TestReportDeduplicatesAndThrottles(t *testing.T) {
+	newJob := func(name string, state v1.ProwJobState) *v1.ProwJob {
+		return &v1.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1.ProwJobSpec{
+				Type: v1.PeriodicJob,
+				Job:  "ci-flaky",
+			},
+			Status: v1.ProwJobStatus{State: state},
+		}
+	}
+	fakeClock := clock.NewFakeClock(time.Now())
+	sr := &slackReporter{
+		config: func(*v1.Refs) config.SlackReporter {
+			return config.SlackReporter{
+				SlackReporterConfig: v1.SlackReporterConfig{
+					Channel:        "alerts",
+					ReportTemplate: "{{.Spec.Job}} {{.Status.State}}",
+				},
+				MinReportInterval: &metav1.Duration{Duration: time.Hour},
+			}
+		},
+		clock: fakeClock,
+	}
+
+	steps := []struct {
+		name         string
+		pj           *v1.ProwJob
+		advance      time.Duration
+		expectReport bool
+	}{
+		{
+			name:         "first failure is reported",
+			pj:           newJob("one", v1.FailureState),
+			expectReport: true,
+		},
+		{
+			name: "same run in same state is deduplicated",
+			pj:   newJob("one", v1.FailureState),
+		},
+		{
+			name:    "next failure within interval is throttled",
+			pj:      newJob("two", v1.FailureState),
+			advance: time.Minute,
+		},
+		{
+			name:         "next failure after interval is reported",
+			pj:           newJob("three", v1.FailureState),
+			advance:      time.Hour,
+			expectReport: true,
+		},
+	}
+	for _, step := range steps {
+		fsc := &fakeSlackClient{}
+		sr.clients = map[string]slackClient{DefaultHostName: fsc}
+		fakeClock.Step(step.advance)
+		if err := sr.report(logrus.NewEntry(logrus.StandardLogger()), step.pj); err != nil {
+			t.Fatalf("%s: reporting failed: %v", step.name, err)
+		}
+		_, reported := fsc.messages["alerts"]
+		if reported != step.expectReport {
+			t.Errorf("%s: expected report to be sent: %t, was sent: %t", step.name, step.expectReport, reported)
+		}
+	}
+}
+
+func TestReportRetriesFailedWrites(t *testing.T) {
+	pj := &v1.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "one"},
+		Spec: v1.ProwJobSpec{
+			Type: v1.PeriodicJob,
+			Job:  "ci-flaky",
+		},
+		Status: v1.ProwJobStatus{State: v1.FailureState},
+	}
+	fsc := &fakeSlackClient{err: errors.New("slack is down")}
+	sr := &slackReporter{
+		config: func(*v1.Refs) config.SlackReporter {
+			return config.SlackReporter{
+				SlackReporterConfig: v1.SlackReporterConfig{
+					Channel:        "alerts",
+					ReportTemplate: "{{.Spec.Job}} {{.Status.State}}",
+				},
+				MinReportInterval: &metav1.Duration{Duration: time.Hour},
+			}
+		},
+		clients: map[string]slackClient{DefaultHostName: fsc},
+		clock:   clock.NewFakeClock(time.Now()),
+	}
+
+	if err := sr.report(logrus.NewEntry(logrus.StandardLogger()), pj); err == nil {
+		t.Fatal("expected the failing write to fail the report")
+	}
+	fsc.err = nil
+	if err := sr.report(logrus.NewEntry(logrus.StandardLogger()), pj); err != nil {
+		t.Fatalf("reporting failed: %v", err)
+	}
+	if _, reported := fsc.messages["alerts"]; !reported {
+		t.Error("expected the retried report to be sent rather than deduplicated")
+	}
+}
+
+func TestLastReportsArePruned(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	sr := &slackReporter{clock: fakeClock}
+	newJob := func(job string) *v1.ProwJob {
+		return &v1.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{Name: job + "-run"},
+			Spec:       v1.ProwJobSpec{Job: job},
+			Status:     v1.ProwJobStatus{State: v1.FailureState},
+		}
+	}
+
+	sr.deduplicateOrThrottle(DefaultHostName, "alerts", newJob("short"), nil)
+	sr.deduplicateOrThrottle(DefaultHostName, "alerts", newJob("long"), &metav1.Duration{Duration: 48 * time.Hour})
+	fakeClock.Step(lastReportsTTL)
+	sr.deduplicateOrThrottle(DefaultHostName, "alerts", newJob("new"), nil)
+
+	var remaining []string
+	for key := range sr.lastReports {
+		remaining = append(remaining, key)
+	}
+	sort.Strings(remaining)
+	expected := []string{"*/alerts/long", "*/alerts/new"}
+	if !reflect.DeepEqual(remaining, expected) {
+		t.Errorf("expected reports %v to be remembered, got %v", expected, remaining)
+	}
+}
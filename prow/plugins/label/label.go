@@ -270,6 +270,15 @@ func handleComment(gc githubClient, log *logrus.Entry, config plugins.Label, e *
 	if len(noSuchLabelsInRepo) > 0 {
 		log.Infof("Labels missing in repo: %v", noSuchLabelsInRepo)
 		msg := fmt.Sprintf("The label(s) `%s` cannot be applied, because the repository doesn't have them.", strings.Join(noSuchLabelsInRepo, ", "))
+		var suggestions []string
+		for _, missing := range noSuchLabelsInRepo {
+			if similar := similarLabels(missing, RepoLabelsExisting); len(similar) > 0 {
+				suggestions = append(suggestions, fmt.Sprintf("- instead of `%s`: `%s`", missing, strings.Join(similar, "`, `")))
+			}
+		}
+		if len(suggestions) > 0 {
+			msg += "\n\nDid you mean one of these existing labels?\n" + strings.Join(suggestions, "\n")
+		}
 		return gc.CreateComment(org, repo, e.Number, plugins.FormatResponseRaw(bodyWithoutComments, e.HTMLURL, e.User.Login, msg))
 	}
 
@@ -287,6 +296,71 @@ func handleComment(gc githubClient, log *logrus.Entry, config plugins.Label, e *
 	return nil
 }
 
+// maxSuggestionDistance returns the largest edit distance between the name part
+// of a requested label and an existing label for the latter to be suggested.
+// Short names allow fewer edits, as they are close to too many other names.
+func maxSuggestionDistance(name string) int {
+	return minInt(2, len([]rune(name))/3)
+}
+
+// similarLabels returns the existing repo labels that are likely what the user
+// meant when requesting a label the repo doesn't have: labels with the same
+// prefix and a name within a small edit distance, or labels with the same name
+// under a different prefix.
+func similarLabels(label string, existing sets.String) []string {
+	prefix, name := splitLabel(label)
+	var similar []string
+	for _, candidate := range existing.List() {
+		candidatePrefix, candidateName := splitLabel(candidate)
+		switch {
+		case candidatePrefix == prefix && editDistance(name, candidateName) <= maxSuggestionDistance(name):
+			similar = append(similar, candidate)
+		case candidatePrefix != prefix && candidateName == name:
+			similar = append(similar, candidate)
+		}
+	}
+	return similar
+}
+
+func splitLabel(label string) (prefix, name string) {
+	if i := strings.Index(label, "/"); i >= 0 {
+		return label[:i], label[i+1:]
+	}
+	return "", label
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
 func canUserSetLabel(ghc githubClient, org string, user string, label string, restrictedLabels map[string]plugins.RestrictedLabel) (canSet bool, canNotSetReason string, err error) {
 	config, isRestricted := restrictedLabels[label]
 	if !isRestricted {
@@ -296,6 +296,30 @@ func TestHandleComment(t *testing.T) {
 			expectedCommentText:   "The label(s) `priority/infra` cannot be applied, because the repository doesn't have them.",
 			action:                github.GenericCommentActionCreated,
 		},
+		{
+			name:                  "Suggest Existing Label With Same Name Under Another Prefix",
+			body:                  "/area urgent",
+			repoLabels:            []string{"area/infra", "area/api", "priority/critical", "priority/urgent"},
+			issueLabels:           []string{},
+			expectedNewLabels:     formatWithPRInfo(),
+			expectedRemovedLabels: []string{},
+			commenter:             orgMember,
+			expectedBotComment:    true,
+			expectedCommentText:   "- instead of `area/urgent`: `priority/urgent`",
+			action:                github.GenericCommentActionCreated,
+		},
+		{
+			name:                  "Suggest Existing Labels Close To A Misspelled Label",
+			body:                  "/kind featur",
+			repoLabels:            []string{"kind/bug", "kind/feature", "kind/features"},
+			issueLabels:           []string{},
+			expectedNewLabels:     formatWithPRInfo(),
+			expectedRemovedLabels: []string{},
+			commenter:             orgMember,
+			expectedBotComment:    true,
+			expectedCommentText:   "Did you mean one of these existing labels?\n- instead of `kind/featur`: `kind/feature`, `kind/features`",
+			action:                github.GenericCommentActionCreated,
+		},
 		{
 			name:                  "Add Multiple Area Labels (Some Valid)",
 			body:                  "/area lgtm infra",
@@ -801,6 +825,41 @@ func TestHandleComment(t *testing.T) {
 	}
 }
 
+func TestSimilarLabels(t *testing.T) {
+	existing := sets.NewString("area/api", "area/infra", "kind/bug", "kind/feature", "priority/critical")
+	testCases := []struct {
+		name     string
+		label    string
+		expected []string
+	}{
+		{
+			name:     "typo in name",
+			label:    "area/instra",
+			expected: []string{"area/infra"},
+		},
+		{
+			name:     "same name under other prefix",
+			label:    "kind/infra",
+			expected: []string{"area/infra"},
+		},
+		{
+			name:  "nothing close",
+			label: "kind/documentation",
+		},
+		{
+			name:  "short names tolerate fewer typos",
+			label: "kind/bgu",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, similarLabels(tc.label, existing)); diff != "" {
+				t.Errorf("unexpected suggestions: %s", diff)
+			}
+		})
+	}
+}
+
 func TestHandleLabelAdd(t *testing.T) {
 	type testCase struct {
 		name              string
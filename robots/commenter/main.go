@@ -20,6 +20,8 @@ limitations under the License.
 // By default commenter runs in dry mode, add --confirm to make it leave comments.
 // The --updated, --include-closed, --ceiling options provide minor safeguards
 // around leaving excessive comments.
+// The --label and --close options additionally label or close each issue
+// after commenting on it.
 package main
 
 import (
@@ -74,6 +76,8 @@ func flagOptions() options {
 	flag.StringVar(&o.graphqlEndpoint, "graphql-endpoint", github.DefaultGraphQLEndpoint, "GitHub's GraphQL API Endpoint")
 	flag.StringVar(&o.token, "token", "", "Path to github token")
 	flag.BoolVar(&o.random, "random", false, "Choose random issues to comment on from the query")
+	flag.Var(&o.labels, "label", "Add this label to matching issues after commenting, may be repeated")
+	flag.BoolVar(&o.close, "close", false, "Close matching issues after commenting if set")
 	flag.Parse()
 	return o
 }
@@ -88,10 +92,12 @@ type meta struct {
 type options struct {
 	asc             bool
 	ceiling         int
+	close           bool
 	comment         string
 	includeArchived bool
 	includeClosed   bool
 	includeLocked   bool
+	labels          flagutil.Strings
 	useTemplate     bool
 	query           string
 	sort            string
@@ -153,6 +159,9 @@ func makeQuery(query string, includeArchived, includeClosed, includeLocked bool,
 }
 
 type client interface {
+	AddLabels(owner, repo string, number int, labels ...string) error
+	CloseIssue(owner, repo string, number int) error
+	ClosePR(owner, repo string, number int) error
 	CreateComment(owner, repo string, number int, comment string) error
 	FindIssues(query, sort string, asc bool) ([]github.Issue, error)
 }
@@ -201,7 +210,7 @@ func main() {
 		asc = true
 	}
 	commenter := makeCommenter(o.comment, o.useTemplate)
-	if err := run(c, query, sort, asc, o.random, commenter, o.ceiling); err != nil {
+	if err := run(c, query, sort, asc, o.random, commenter, o.ceiling, o.labels.Strings(), o.close); err != nil {
 		log.Fatalf("Failed run: %v", err)
 	}
 }
@@ -220,7 +229,7 @@ func makeCommenter(comment string, useTemplate bool) func(meta) (string, error)
 	}
 }
 
-func run(c client, query, sort string, asc, random bool, commenter func(meta) (string, error), ceiling int) error {
+func run(c client, query, sort string, asc, random bool, commenter func(meta) (string, error), ceiling int, labels []string, closeIssues bool) error {
 	log.Printf("Searching: %s", query)
 	issues, err := c.FindIssues(query, sort, asc)
 	if err != nil {
@@ -261,6 +270,28 @@ func run(c client, query, sort string, asc, random bool, commenter func(meta) (s
 			continue
 		}
 		log.Printf("Commented on %s", i.HTMLURL)
+		if len(labels) > 0 {
+			if err := c.AddLabels(org, repo, number, labels...); err != nil {
+				msg := fmt.Sprintf("Failed to label %s/%s#%d: %v", org, repo, number, err)
+				log.Print(msg)
+				problems = append(problems, msg)
+				continue
+			}
+			log.Printf("Labeled %s with %v", i.HTMLURL, labels)
+		}
+		if closeIssues {
+			closeIssue := c.CloseIssue
+			if i.IsPullRequest() {
+				closeIssue = c.ClosePR
+			}
+			if err := closeIssue(org, repo, number); err != nil {
+				msg := fmt.Sprintf("Failed to close %s/%s#%d: %v", org, repo, number, err)
+				log.Print(msg)
+				problems = append(problems, msg)
+				continue
+			}
+			log.Printf("Closed %s", i.HTMLURL)
+		}
 	}
 	if len(problems) > 0 {
 		return fmt.Errorf("encoutered %d failures: %v", len(problems), problems)
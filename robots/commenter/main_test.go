@@ -19,6 +19,7 @@ package main
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
@@ -210,6 +211,35 @@ func makeIssue(owner, repo string, number int, title string) github.Issue {
 type fakeClient struct {
 	comments []int
 	issues   []github.Issue
+	labeled  map[int][]string
+	closed   []int
+	prs      []int
+}
+
+// Fakes labeling an issue, using the same signature as github.Client
+func (c *fakeClient) AddLabels(owner, repo string, number int, labels ...string) error {
+	for _, l := range labels {
+		if l == "error" {
+			return errors.New(l)
+		}
+	}
+	if c.labeled == nil {
+		c.labeled = map[int][]string{}
+	}
+	c.labeled[number] = append(c.labeled[number], labels...)
+	return nil
+}
+
+// Fakes closing an issue, using the same signature as github.Client
+func (c *fakeClient) CloseIssue(owner, repo string, number int) error {
+	c.closed = append(c.closed, number)
+	return nil
+}
+
+// Fakes closing a pull request, using the same signature as github.Client
+func (c *fakeClient) ClosePR(owner, repo string, number int) error {
+	c.prs = append(c.prs, number)
+	return nil
 }
 
 // Fakes Creating a client, using the same signature as github.Client
@@ -314,7 +344,7 @@ func TestRun(t *testing.T) {
 	for _, tc := range cases {
 		ignoreSorting := ""
 		ignoreOrder := false
-		err := run(&tc.client, tc.query, ignoreSorting, ignoreOrder, false, makeCommenter(tc.comment, tc.template), tc.ceiling)
+		err := run(&tc.client, tc.query, ignoreSorting, ignoreOrder, false, makeCommenter(tc.comment, tc.template), tc.ceiling, nil, false)
 		if tc.err && err == nil {
 			t.Errorf("%s: failed to received an error", tc.name)
 			continue
@@ -346,6 +376,76 @@ func TestRun(t *testing.T) {
 	}
 }
 
+func TestRunLabelAndClose(t *testing.T) {
+	pr := makeIssue("o", "r", 3, "stale pull")
+	pr.PullRequest = &struct{}{}
+	issues := []github.Issue{
+		makeIssue("o", "r", 1, "stale issue"),
+		makeIssue("o", "r", 2, "stale issue"),
+		pr,
+	}
+
+	cases := []struct {
+		name     string
+		labels   []string
+		close    bool
+		labeled  map[int][]string
+		closed   []int
+		prs      []int
+		comments []int
+		err      bool
+	}{
+		{
+			name:     "comment only",
+			comments: []int{1, 2, 3},
+		},
+		{
+			name:     "label matches",
+			labels:   []string{"lifecycle/stale", "triage/needs-information"},
+			comments: []int{1, 2, 3},
+			labeled: map[int][]string{
+				1: {"lifecycle/stale", "triage/needs-information"},
+				2: {"lifecycle/stale", "triage/needs-information"},
+				3: {"lifecycle/stale", "triage/needs-information"},
+			},
+		},
+		{
+			name:     "close issues and pull requests",
+			close:    true,
+			comments: []int{1, 2, 3},
+			closed:   []int{1, 2},
+			prs:      []int{3},
+		},
+		{
+			name:     "label failure skips closing",
+			labels:   []string{"error"},
+			close:    true,
+			comments: []int{1, 2, 3},
+			err:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		client := fakeClient{issues: issues}
+		err := run(&client, "stale", "", false, false, makeCommenter("closing", false), 0, tc.labels, tc.close)
+		if tc.err != (err != nil) {
+			t.Errorf("%s: expected error %t, got %v", tc.name, tc.err, err)
+		}
+		if !reflect.DeepEqual(tc.comments, client.comments) {
+			t.Errorf("%s: expected comments %v != actual %v", tc.name, tc.comments, client.comments)
+		}
+		if !reflect.DeepEqual(tc.labeled, client.labeled) {
+			t.Errorf("%s: expected labels %v != actual %v", tc.name, tc.labeled, client.labeled)
+		}
+		if !reflect.DeepEqual(tc.closed, client.closed) {
+			t.Errorf("%s: expected closed issues %v != actual %v", tc.name, tc.closed, client.closed)
+		}
+		if !reflect.DeepEqual(tc.prs, client.prs) {
+			t.Errorf("%s: expected closed pull requests %v != actual %v", tc.name, tc.prs, client.prs)
+		}
+	}
+}
+
 func TestMakeCommenter(t *testing.T) {
 	m := meta{
 		Number: 10,
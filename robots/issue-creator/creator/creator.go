@@ -38,6 +38,7 @@ type RepoClient interface {
 	GetRepoLabels(org, repo string) ([]*github.Label, error)
 	GetIssues(org, repo string, options *github.IssueListByRepoOptions) ([]*github.Issue, error)
 	CreateIssue(org, repo, title, body string, labels, owners []string) (*github.Issue, error)
+	CloseIssue(org, repo string, number int, comment string) (*github.Issue, error)
 	GetCollaborators(org, repo string) ([]*github.User, error)
 }
 
@@ -63,6 +64,10 @@ func (c githubClient) CreateIssue(org, repo, title, body string, labels, owners
 	return c.Client.CreateIssue(org, repo, title, body, labels, owners)
 }
 
+func (c githubClient) CloseIssue(org, repo string, number int, comment string) (*github.Issue, error) {
+	return c.Client.CloseIssue(org, repo, number, comment)
+}
+
 // OwnerMapper finds an owner for a given test name.
 type OwnerMapper interface {
 	// TestOwner returns a GitHub username for a test, or "" if none are found.
@@ -101,6 +106,19 @@ type IssueSource interface {
 	RegisterFlags()
 }
 
+// RecoveringSource is an IssueSource that can tell when the failure behind an issue it filed has
+// stopped occurring, so that the IssueCreator can close the issue.
+type RecoveringSource interface {
+	IssueSource
+	// Recovered returns true iff the open github issue was filed by this source for a failure that
+	// no longer occurs. It is only called after Issues and only for issues authored by this bot
+	// that do not contain the ID() of any of the issues Issues returned.
+	Recovered(issue *github.Issue) bool
+}
+
+// recoveredComment is left on issues when they are closed because their failure recovered.
+const recoveredComment = "The failure this issue was filed for is no longer occurring, closing.\n\nPlease reopen this issue if the failure comes back."
+
 // IssueCreator handles syncing identified issues with github issues.
 // This includes finding existing github issues, creating new ones, and ensuring that duplicate
 // github issues are not created.
@@ -127,6 +145,8 @@ type IssueCreator struct {
 	tokenFile string
 	// dryRun is true iff no modifying or 'write' operations should be made to github.
 	dryRun bool
+	// closeRecovered is true iff open issues whose failure recovered should be closed.
+	closeRecovered bool
 	// project is the name of the github repo.
 	project string
 	// org is the github organization that owns the repo.
@@ -211,6 +231,11 @@ func (c *IssueCreator) CreateAndSync() {
 			len(issues),
 			srcName,
 		)
+
+		if rs, ok := src.(RecoveringSource); ok && c.closeRecovered {
+			closed := c.closeRecoveredIssues(rs, issues)
+			glog.Infof("Closed %d recovered issues from source: %s.", closed, srcName)
+		}
 	}
 }
 
@@ -285,6 +310,7 @@ func (c *IssueCreator) RegisterFlags() {
 	flag.StringVar(&c.project, "project", "", "The name of the github repo to create issues in.")
 	flag.StringVar(&c.org, "org", "", "The name of the organization that owns the repo to create issues in.")
 	flag.BoolVar(&c.dryRun, "dry-run", true, "True iff only 'read' operations should be made on github.")
+	flag.BoolVar(&c.closeRecovered, "close-recovered", false, "True iff open issues should be closed once their source reports the failure recovered.")
 
 	for _, src := range sources {
 		src.RegisterFlags()
@@ -377,6 +403,44 @@ func (c *IssueCreator) sync(issue Issue) bool {
 	return true
 }
 
+// closeRecoveredIssues closes the open issues authored by this bot that src reports as recovered.
+// Issues containing the ID of one of the issues that were just synced are never closed.
+// The number of closed issues is returned.
+func (c *IssueCreator) closeRecoveredIssues(src RecoveringSource, issues []Issue) int {
+	closed := 0
+	for number, i := range c.allIssues {
+		if i.State == nil || *i.State != "open" || i.Body == nil {
+			continue
+		}
+		current := false
+		for _, issue := range issues {
+			if strings.Contains(*i.Body, issue.ID()) {
+				current = true
+				break
+			}
+		}
+		if current || !src.Recovered(i) {
+			continue
+		}
+
+		glog.Infof("Close Recovered Issue: #%d\n", number)
+		if c.dryRun {
+			closed++
+			continue
+		}
+		updated, err := c.client.CloseIssue(c.org, c.project, number, recoveredComment)
+		if err != nil {
+			glog.Errorf("Failed to close recovered github issue #%d: %v.\n", number, err)
+			continue
+		}
+		if updated != nil {
+			c.allIssues[number] = updated
+		}
+		closed++
+	}
+	return closed
+}
+
 // TestSIG uses the IssueCreator's OwnerMapper to look up the SIG for a test.
 func (c *IssueCreator) TestSIG(testName string) string {
 	if c.Owners == nil {
@@ -39,6 +39,7 @@ type fakeClient struct {
 	org        string
 	project    string
 	t          *testing.T
+	closed     map[int]string
 }
 
 func (c *fakeClient) GetUser(login string) (*github.User, error) {
@@ -77,6 +78,21 @@ func (c *fakeClient) CreateIssue(org, repo string, title, body string, labels, o
 	return issue, nil
 }
 
+func (c *fakeClient) CloseIssue(org, repo string, number int, comment string) (*github.Issue, error) {
+	for _, issue := range c.issues {
+		if *issue.Number == number {
+			closed := "closed"
+			issue.State = &closed
+			if c.closed == nil {
+				c.closed = map[int]string{}
+			}
+			c.closed[number] = comment
+			return issue, nil
+		}
+	}
+	return nil, fmt.Errorf("issue #%d does not exist", number)
+}
+
 func (c *fakeClient) GetCollaborators(org, repo string) ([]*github.User, error) {
 	return nil, errors.New("some error (allow all assignees)")
 }
@@ -260,6 +276,68 @@ func TestIssueCreator(t *testing.T) {
 	}
 }
 
+// fakeSource is a RecoveringSource that reports issues containing "<RECOVERED>" as recovered.
+type fakeSource struct {
+	issues []Issue
+}
+
+func (s *fakeSource) Issues(*IssueCreator) ([]Issue, error) {
+	return s.issues, nil
+}
+
+func (s *fakeSource) RegisterFlags() {}
+
+func (s *fakeSource) Recovered(issue *github.Issue) bool {
+	return strings.Contains(*issue.Body, "<RECOVERED>")
+}
+
+func TestCloseRecoveredIssues(t *testing.T) {
+	current := &fakeIssue{title: "title0", body: "body<ID0><RECOVERED>", id: "<ID0>"}
+	c := &fakeClient{
+		t:        t,
+		userName: "BOT_USERNAME",
+		issues: []*github.Issue{
+			makeTestIssue(current.title, current.body, "open", nil, nil, 0),
+			makeTestIssue("title1", "body<ID1><RECOVERED>", "open", nil, nil, 1),
+			makeTestIssue("title2", "body<ID2>", "open", nil, nil, 2),
+			makeTestIssue("title3", "body<ID3><RECOVERED>", "closed", nil, nil, 3),
+		},
+	}
+	creator := &IssueCreator{
+		client: c,
+	}
+	if err := creator.loadCache(); err != nil {
+		t.Fatalf("IssueCreator failed to load data from github while initing: %v", err)
+	}
+	src := &fakeSource{issues: []Issue{current}}
+
+	// Test that DryRun prevents issues from being closed.
+	creator.dryRun = true
+	if closed := creator.closeRecoveredIssues(src, src.issues); closed != 1 {
+		t.Errorf("Expected a dry run to report 1 recovered issue, but got %d.\n", closed)
+	}
+	if len(c.closed) > 0 {
+		t.Errorf("closeRecoveredIssues with DryRun on should not have closed %v!\n", c.closed)
+	}
+
+	// Test that only open recovered issues which are not currently failing are closed.
+	creator.dryRun = false
+	if closed := creator.closeRecoveredIssues(src, src.issues); closed != 1 {
+		t.Errorf("Expected 1 recovered issue to be closed, but got %d.\n", closed)
+	}
+	if expected := map[int]string{1: recoveredComment}; !reflect.DeepEqual(c.closed, expected) {
+		t.Errorf("Expected closed issues %v, but got %v.\n", expected, c.closed)
+	}
+	if state := *creator.allIssues[1].State; state != "closed" {
+		t.Errorf("Expected the cached state of issue #1 to be closed, but it was %q.\n", state)
+	}
+
+	// Test that closed issues are not closed again.
+	if closed := creator.closeRecoveredIssues(src, src.issues); closed != 0 {
+		t.Errorf("Expected no issues to be closed again, but got %d.\n", closed)
+	}
+}
+
 func makeTestIssue(title, body, state string, labels, owners []string, number int) *github.Issue {
 	return &github.Issue{
 		Title:     &title,
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"regexp"
 	"sort"
 	"time"
 
@@ -59,8 +60,13 @@ type FlakyJobReporter struct {
 	syncCount       int
 
 	creator *creator.IssueCreator
+	// flakeCounts maps the name of every job in the latest flaky job data to its flake count.
+	flakeCounts map[string]int
 }
 
+// flakyJobIDRE matches the ID() of a FlakyJob in the body of an issue, capturing the job name.
+var flakyJobIDRE = regexp.MustCompile(`Flaky Job: (\S+)`)
+
 func init() {
 	creator.RegisterSourceOrDie("flakyjob-reporter", &FlakyJobReporter{})
 }
@@ -97,8 +103,21 @@ func (fjr *FlakyJobReporter) Issues(c *creator.IssueCreator) ([]creator.Issue, e
 	return issues, nil
 }
 
+// Recovered returns true iff the issue was filed for a flaky job that no longer flaked in the
+// past week according to the latest flaky job data.
+func (fjr *FlakyJobReporter) Recovered(issue *githubapi.Issue) bool {
+	if issue.Body == nil {
+		return false
+	}
+	mat := flakyJobIDRE.FindStringSubmatch(*issue.Body)
+	if mat == nil {
+		return false
+	}
+	return fjr.flakeCounts[mat[1]] == 0
+}
+
 // parseFlakyJobs parses JSON generated by the 'flakes' bigquery metric into a sorted slice of
-// *FlakyJob.
+// *FlakyJob. The flake counts of all parsed jobs are remembered for Recovered.
 func (fjr *FlakyJobReporter) parseFlakyJobs(jsonIn []byte) ([]*FlakyJob, error) {
 	var flakeMap map[string]*FlakyJob
 	err := json.Unmarshal(jsonIn, &flakeMap)
@@ -133,6 +152,11 @@ func (fjr *FlakyJobReporter) parseFlakyJobs(jsonIn []byte) ([]*FlakyJob, error)
 		flakyJobs = append(flakyJobs, fj)
 	}
 
+	fjr.flakeCounts = make(map[string]int, len(flakyJobs))
+	for _, fj := range flakyJobs {
+		fjr.flakeCounts[fj.Name] = *fj.FlakeCount
+	}
+
 	sort.SliceStable(flakyJobs, func(i, j int) bool {
 		if *flakyJobs[i].FlakeCount == *flakyJobs[j].FlakeCount {
 			return *flakyJobs[i].Consistency < *flakyJobs[j].Consistency
@@ -134,6 +134,31 @@ func TestFJPrevCloseInWindow(t *testing.T) {
 	}
 }
 
+func TestFJRecovered(t *testing.T) {
+	reporter := &FlakyJobReporter{creator: &creator.IssueCreator{}}
+	fjs, err := reporter.parseFlakyJobs(sampleFlakyJobJSON)
+	if err != nil {
+		t.Fatalf("Error parsing flaky jobs: %v\n", err)
+	}
+
+	flaking := fjs[0].Body(nil)
+	recovered := "### Flaky Job: ci-kubernetes-e2e-gone\n Flakes in the past week: **12**\n"
+	unrelated := "### Failure cluster [key_hash](https://go.k8s.io/triage#key_hash)\n"
+	for _, tc := range []struct {
+		body     string
+		expected bool
+	}{
+		{body: flaking, expected: false},
+		{body: recovered, expected: true},
+		{body: unrelated, expected: false},
+	} {
+		body := tc.body
+		if actual := reporter.Recovered(&githubapi.Issue{Body: &body}); actual != tc.expected {
+			t.Errorf("Expected Recovered to return %t for issue body %q, but got %t.", tc.expected, tc.body, actual)
+		}
+	}
+}
+
 func checkFlakyJobsSorted(jobs []*FlakyJob) bool {
 	for i := 1; i < len(jobs); i++ {
 		if *jobs[i-1].FlakeCount < *jobs[i].FlakeCount {
@@ -22,6 +22,7 @@ import (
 	"flag"
 	"fmt"
 	"reflect"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -57,6 +58,9 @@ func init() {
 	creator.RegisterSourceOrDie("triage-filer", &TriageFiler{})
 }
 
+// triageIDRE matches the cluster heading in the body of an issue, capturing the cluster ID.
+var triageIDRE = regexp.MustCompile(`### Failure cluster \[([^\]]+)\]`)
+
 // Issues is the main work function of the TriageFiler.  It fetches and parses cluster data,
 // then syncs the top issues to github with the IssueCreator.
 func (f *TriageFiler) Issues(c *creator.IssueCreator) ([]creator.Issue, error) {
@@ -77,6 +81,24 @@ func (f *TriageFiler) Issues(c *creator.IssueCreator) ([]creator.Issue, error) {
 	return issues, nil
 }
 
+// Recovered returns true iff the issue was filed for a failure cluster that has no failures left
+// within the sliding time window of the latest triage data.
+func (f *TriageFiler) Recovered(issue *githubapi.Issue) bool {
+	if issue.Body == nil || f.data == nil {
+		return false
+	}
+	mat := triageIDRE.FindStringSubmatch(*issue.Body)
+	if mat == nil {
+		return false
+	}
+	for _, clust := range f.data.Clustered {
+		if clust.Identifier == mat[1] {
+			return false
+		}
+	}
+	return true
+}
+
 // RegisterFlags registers options for this munger; returns any that require a restart when changed.
 func (f *TriageFiler) RegisterFlags() {
 	flag.IntVar(&f.topClustersCount, "triage-count", 3, "The number of clusters to sync issues for on github.")
@@ -200,6 +200,31 @@ func TestTFParserSimple(t *testing.T) {
 	checkCluster(issues[0], t)
 }
 
+func TestTFRecovered(t *testing.T) {
+	f := NewTestTriageFiler()
+	clusters, err := f.loadClusters(json1issue2job2test)
+	if err != nil {
+		t.Fatalf("Error parsing triage data: %v\n", err)
+	}
+
+	failing := clusters[0].Body(nil)
+	recovered := "### Failure cluster [other_hash](https://go.k8s.io/triage#other_hash)\n"
+	unrelated := "### Flaky Job: ci-kubernetes-e2e-gce\n"
+	for _, tc := range []struct {
+		body     string
+		expected bool
+	}{
+		{body: failing, expected: false},
+		{body: recovered, expected: true},
+		{body: unrelated, expected: false},
+	} {
+		body := tc.body
+		if actual := f.Recovered(&github.Issue{Body: &body}); actual != tc.expected {
+			t.Errorf("Expected Recovered to return %t for issue body %q, but got %t.", tc.expected, tc.body, actual)
+		}
+	}
+}
+
 func checkBuildStart(t *testing.T, f *TriageFiler, jobName string, build int, expected int64) {
 	row, err := f.data.Builds.Jobs[jobName].rowForBuild(build)
 	if err != nil {
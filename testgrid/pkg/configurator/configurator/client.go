@@ -28,6 +28,7 @@ import (
 
 	tgCfgUtil "github.com/GoogleCloudPlatform/testgrid/config"
 	"github.com/GoogleCloudPlatform/testgrid/config/yamlcfg"
+	configpb "github.com/GoogleCloudPlatform/testgrid/pb/config"
 	"github.com/GoogleCloudPlatform/testgrid/util/gcs"
 	prowConfig "k8s.io/test-infra/prow/config"
 	"k8s.io/test-infra/testgrid/pkg/configurator/options"
@@ -35,6 +36,7 @@ import (
 
 	"cloud.google.com/go/storage"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/api/iterator"
 	"sigs.k8s.io/yaml"
 )
 
@@ -129,6 +131,55 @@ func write(ctx context.Context, client *storage.Client, path string, bytes []byt
 	return nil
 }
 
+// validateGCSPrefixes returns an error listing the test groups that have no results under their
+// GCS prefixes, according to exists. Test groups that are not declared, but generated from prow
+// job annotations, may belong to jobs that did not run yet and are only warned about.
+func validateGCSPrefixes(c *configpb.Configuration, declared map[string]bool, exists func(bucket, prefix string) (bool, error)) error {
+	var missing []string
+	for _, tg := range c.TestGroups {
+		// gcs_prefix may list several comma-separated prefixes
+		for _, prefix := range strings.Split(tg.GcsPrefix, ",") {
+			prefix = strings.TrimSpace(prefix)
+			if prefix == "" {
+				continue
+			}
+			parts := strings.SplitN(prefix, "/", 2)
+			var object string
+			if len(parts) == 2 {
+				// Match whole path segments, so that logs/ci-foo does not match logs/ci-foo-bar.
+				object = strings.TrimSuffix(parts[1], "/") + "/"
+			}
+			found, err := exists(parts[0], object)
+			if err != nil {
+				return fmt.Errorf("could not check gcs_prefix %s of test group %s: %w", prefix, tg.Name, err)
+			}
+			if !found && !declared[tg.Name] {
+				logrus.WithFields(logrus.Fields{"test-group": tg.Name, "gcs-prefix": prefix}).Warn("No results under gcs_prefix of test group generated from prow job annotations.")
+			} else if !found {
+				missing = append(missing, fmt.Sprintf("%s (%s)", tg.Name, prefix))
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("test groups with no results under their gcs_prefix: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// gcsPrefixExists returns a function that checks whether any object in bucket starts with prefix.
+func gcsPrefixExists(ctx context.Context, client *storage.Client) func(bucket, prefix string) (bool, error) {
+	return func(bucket, prefix string) (bool, error) {
+		_, err := client.Bucket(bucket).Objects(ctx, &storage.Query{Prefix: prefix}).Next()
+		if err == iterator.Done {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+}
+
 // Ignores what changed for now and recomputes everything
 func doOneshot(ctx context.Context, opt *options.Options, prowConfigAgent *prowConfig.Agent) error {
 	// Set up GCS client if output is to GCS
@@ -162,6 +213,10 @@ func doOneshot(ctx context.Context, opt *options.Options, prowConfigAgent *prowC
 		ProwJobURLPrefix:      opt.ProwJobURLPrefix,
 	}
 
+	declared := map[string]bool{}
+	for _, tg := range c.TestGroups {
+		declared[tg.Name] = true
+	}
 	if prowConfigAgent != nil {
 		pac.ProwConfig = prowConfigAgent.Config()
 		if err := pac.ApplyProwjobAnnotations(&c); err != nil {
@@ -170,7 +225,21 @@ func doOneshot(ctx context.Context, opt *options.Options, prowConfigAgent *prowC
 	}
 
 	if opt.ValidateConfigFile {
-		return tgCfgUtil.Validate(&c)
+		if err := tgCfgUtil.Validate(&c); err != nil {
+			return err
+		}
+		if !opt.ValidateGCSPrefixes {
+			return nil
+		}
+		var creds []string
+		if opt.Creds != "" {
+			creds = append(creds, opt.Creds)
+		}
+		client, err := gcs.ClientWithCreds(ctx, creds...)
+		if err != nil {
+			return fmt.Errorf("failed to create gcs client: %w", err)
+		}
+		return validateGCSPrefixes(&c, declared, gcsPrefixExists(ctx, client))
 	}
 
 	// Print proto if requested
@@ -18,10 +18,14 @@ package configurator
 
 import (
 	"context"
+	"errors"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 	"time"
+
+	configpb "github.com/GoogleCloudPlatform/testgrid/pb/config"
 )
 
 func Test_announceChanges(t *testing.T) {
@@ -91,3 +95,99 @@ func Test_announceChanges(t *testing.T) {
 		})
 	}
 }
+
+func Test_validateGCSPrefixes(t *testing.T) {
+	objects := []string{
+		"bucket/logs/ci-present/1/finished.json",
+		"bucket/logs/ci-present-longer/1/finished.json",
+		"other-bucket/logs/ci-present/1/finished.json",
+	}
+	exists := func(bucket, prefix string) (bool, error) {
+		if bucket == "broken" {
+			return false, errors.New("injected error")
+		}
+		for _, o := range objects {
+			if strings.HasPrefix(o, bucket+"/"+prefix) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	tests := []struct {
+		name       string
+		testGroups []*configpb.TestGroup
+		generated  []string
+		expectErr  bool
+	}{
+		{
+			name: "Existing prefixes",
+			testGroups: []*configpb.TestGroup{
+				{Name: "present", GcsPrefix: "bucket/logs/ci-present"},
+				{Name: "several", GcsPrefix: "bucket/logs/ci-present, other-bucket/logs/ci-present"},
+			},
+		},
+		{
+			name: "Missing prefix",
+			testGroups: []*configpb.TestGroup{
+				{Name: "present", GcsPrefix: "bucket/logs/ci-present"},
+				{Name: "missing", GcsPrefix: "bucket/logs/ci-missing"},
+			},
+			expectErr: true,
+		},
+		{
+			name: "Prefix of a longer prefix is missing",
+			testGroups: []*configpb.TestGroup{
+				{Name: "prefix", GcsPrefix: "bucket/logs/ci-pres"},
+			},
+			expectErr: true,
+		},
+		{
+			name: "Trailing slash",
+			testGroups: []*configpb.TestGroup{
+				{Name: "present", GcsPrefix: "bucket/logs/ci-present/"},
+			},
+		},
+		{
+			name: "Missing prefix of a generated test group",
+			testGroups: []*configpb.TestGroup{
+				{Name: "present", GcsPrefix: "bucket/logs/ci-present"},
+				{Name: "new-job", GcsPrefix: "bucket/logs/new-job"},
+			},
+			generated: []string{"new-job"},
+		},
+		{
+			name: "One of several prefixes missing",
+			testGroups: []*configpb.TestGroup{
+				{Name: "several", GcsPrefix: "bucket/logs/ci-present,bucket/logs/ci-missing"},
+			},
+			expectErr: true,
+		},
+		{
+			name: "Error checking prefix",
+			testGroups: []*configpb.TestGroup{
+				{Name: "broken", GcsPrefix: "broken/logs/ci-present"},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			declared := map[string]bool{}
+			for _, tg := range test.testGroups {
+				declared[tg.Name] = true
+			}
+			for _, name := range test.generated {
+				delete(declared, name)
+			}
+			err := validateGCSPrefixes(&configpb.Configuration{TestGroups: test.testGroups}, declared, exists)
+			if test.expectErr && err == nil {
+				t.Error("Expected an error, but got none")
+			}
+			if !test.expectErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
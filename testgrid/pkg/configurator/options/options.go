@@ -39,19 +39,20 @@ func (m *MultiString) Set(v string) error {
 }
 
 type Options struct {
-	Creds              string
-	Inputs             MultiString
-	Oneshot            bool
-	Output             flagutil.Strings
-	PrintText          bool
-	ValidateConfigFile bool
-	WorldReadable      bool
-	WriteYAML          bool
-	ProwConfig         configflagutil.ConfigOptions
-	DefaultYAML        string
-	UpdateDescription  bool
-	ProwJobURLPrefix   string
-	StrictUnmarshal    bool
+	Creds               string
+	Inputs              MultiString
+	Oneshot             bool
+	Output              flagutil.Strings
+	PrintText           bool
+	ValidateConfigFile  bool
+	ValidateGCSPrefixes bool
+	WorldReadable       bool
+	WriteYAML           bool
+	ProwConfig          configflagutil.ConfigOptions
+	DefaultYAML         string
+	UpdateDescription   bool
+	ProwJobURLPrefix    string
+	StrictUnmarshal     bool
 }
 
 func (o *Options) GatherOptions(fs *flag.FlagSet, args []string) error {
@@ -60,6 +61,7 @@ func (o *Options) GatherOptions(fs *flag.FlagSet, args []string) error {
 	fs.Var(&o.Output, "output", "write proto to gs://bucket/obj or /local/path")
 	fs.BoolVar(&o.PrintText, "print-text", false, "print generated info in text format to stdout")
 	fs.BoolVar(&o.ValidateConfigFile, "validate-config-file", false, "validate that the given config files are syntactically correct and exit (proto is not written anywhere)")
+	fs.BoolVar(&o.ValidateGCSPrefixes, "validate-gcs-prefixes", false, "with --validate-config-file, also validate that every test group has results under its gcs_prefix")
 	fs.BoolVar(&o.WorldReadable, "world-readable", false, "when uploading the proto to GCS, makes it world readable. Has no effect on writing to the local filesystem.")
 	fs.BoolVar(&o.WriteYAML, "output-yaml", false, "Output to TestGrid YAML instead of config proto")
 	fs.Var(&o.Inputs, "yaml", "comma-separated list of input YAML files or directories")
@@ -85,6 +87,9 @@ func (o *Options) GatherOptions(fs *flag.FlagSet, args []string) error {
 	if o.ValidateConfigFile && len(o.Output.Strings()) > 0 {
 		return errors.New("--validate-config-file doesn't write the proto anywhere")
 	}
+	if o.ValidateGCSPrefixes && !o.ValidateConfigFile {
+		return errors.New("--validate-gcs-prefixes requires --validate-config-file")
+	}
 	if err := o.ProwConfig.ValidateConfigOptional(); err != nil {
 		return err
 	}
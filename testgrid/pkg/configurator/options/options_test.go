@@ -93,6 +93,25 @@ func Test_Options(t *testing.T) {
 				ValidateConfigFile: true,
 			},
 		},
+		{
+			name: "Validate GCS prefixes",
+			args: []string{"--yaml=file.yaml", "--validate-config-file", "--validate-gcs-prefixes"},
+			expected: &Options{
+				Inputs:      []string{"file.yaml"},
+				DefaultYAML: "file.yaml",
+				ProwConfig: configflagutil.ConfigOptions{
+					ConfigPathFlagName:                    "prow-config",
+					JobConfigPathFlagName:                 "prow-job-config",
+					SupplementalProwConfigsFileNameSuffix: "_prowconfig.yaml",
+				},
+				ValidateConfigFile:  true,
+				ValidateGCSPrefixes: true,
+			},
+		},
+		{
+			name: "--validate-gcs-prefixes without --validate-config-file: fails",
+			args: []string{"--yaml=file.yaml", "--print-text", "--validate-gcs-prefixes"},
+		},
 		{
 			name: "--validate-config-file with output: fails",
 			args: []string{"--yaml=file.yaml", "--validate-config-file", "--output=/foo/bar"},
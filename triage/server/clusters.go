@@ -0,0 +1,192 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// failureData is the subset of the failure_data.json written by the summarizer that the server uses.
+type failureData struct {
+	Clustered []struct {
+		Key   string `json:"key"`
+		ID    string `json:"id"`
+		Text  string `json:"text"`
+		Owner string `json:"owner"`
+		Tests []struct {
+			Name string `json:"name"`
+			Jobs []struct {
+				Name   string   `json:"name"`
+				Builds []string `json:"builds"`
+			} `json:"jobs"`
+		} `json:"tests"`
+	} `json:"clustered"`
+	Builds struct {
+		// Jobs maps a job name to either a {"build number": column index} object or a
+		// [first build number, build count, first column index] array.
+		Jobs map[string]json.RawMessage `json:"jobs"`
+		Cols struct {
+			Started []int64 `json:"started"`
+		} `json:"cols"`
+	} `json:"builds"`
+}
+
+// failureCount is the number of failures of a test or job within a cluster.
+type failureCount struct {
+	Name     string `json:"name"`
+	Failures int    `json:"failures"`
+}
+
+/*
+clusterSummary summarizes a cluster as it is served by the API.
+
+	failures:   the number of test failures in the cluster
+	builds:     the number of distinct builds with a failure in the cluster
+	tests:      the failing tests, sorted by most failures
+	jobs:       the affected jobs, sorted by most failing builds
+	first_seen: the start time of the earliest failing build, in seconds since the epoch
+	last_seen:  the start time of the latest failing build, in seconds since the epoch
+*/
+type clusterSummary struct {
+	ID        string         `json:"id"`
+	Key       string         `json:"key"`
+	Text      string         `json:"text"`
+	Owner     string         `json:"owner"`
+	Failures  int            `json:"failures"`
+	Builds    int            `json:"builds"`
+	Tests     []failureCount `json:"tests"`
+	Jobs      []failureCount `json:"jobs"`
+	FirstSeen int64          `json:"first_seen"`
+	LastSeen  int64          `json:"last_seen"`
+}
+
+// parseFailureData decodes the contents of a failure_data.json file.
+func parseFailureData(contents []byte) (*failureData, error) {
+	var data failureData
+	if err := json.Unmarshal(contents, &data); err != nil {
+		return nil, fmt.Errorf("Could not unmarshal failure data: %s", err)
+	}
+	return &data, nil
+}
+
+// buildStarts returns a function that looks up the start time of a job's build, or false if the
+// build is not in the failure data.
+func (data *failureData) buildStarts() (func(job, build string) (int64, bool), error) {
+	dense := map[string][3]int{}
+	sparse := map[string]map[string]int{}
+	for job, raw := range data.Builds.Jobs {
+		var run [3]int
+		if err := json.Unmarshal(raw, &run); err == nil {
+			dense[job] = run
+			continue
+		}
+		var indexes map[string]int
+		if err := json.Unmarshal(raw, &indexes); err != nil {
+			return nil, fmt.Errorf("Could not parse the builds of job '%s': %s", job, err)
+		}
+		sparse[job] = indexes
+	}
+
+	started := data.Builds.Cols.Started
+	return func(job, build string) (int64, bool) {
+		index := -1
+		if run, ok := dense[job]; ok {
+			number, err := strconv.Atoi(build)
+			if err == nil && number >= run[0] && number < run[0]+run[1] {
+				index = run[2] + number - run[0]
+			}
+		} else if i, ok := sparse[job][build]; ok {
+			index = i
+		}
+		if index < 0 || index >= len(started) {
+			return 0, false
+		}
+		return started[index], true
+	}, nil
+}
+
+// summarizeClusters summarizes every cluster in the failure data, sorted by most failures.
+func summarizeClusters(data *failureData) ([]clusterSummary, error) {
+	started, err := data.buildStarts()
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]clusterSummary, 0, len(data.Clustered))
+	for _, clust := range data.Clustered {
+		summary := clusterSummary{
+			ID:    clust.ID,
+			Key:   clust.Key,
+			Text:  clust.Text,
+			Owner: clust.Owner,
+		}
+		// Maps jobs to the set of their builds that failed within this cluster
+		jobBuilds := map[string]map[string]bool{}
+		for _, t := range clust.Tests {
+			failures := 0
+			for _, j := range t.Jobs {
+				failures += len(j.Builds)
+				if jobBuilds[j.Name] == nil {
+					jobBuilds[j.Name] = map[string]bool{}
+				}
+				for _, b := range j.Builds {
+					jobBuilds[j.Name][b] = true
+					start, ok := started(j.Name, b)
+					if !ok {
+						continue
+					}
+					if summary.FirstSeen == 0 || start < summary.FirstSeen {
+						summary.FirstSeen = start
+					}
+					if start > summary.LastSeen {
+						summary.LastSeen = start
+					}
+				}
+			}
+			summary.Failures += failures
+			summary.Tests = append(summary.Tests, failureCount{t.Name, failures})
+		}
+		for j, builds := range jobBuilds {
+			summary.Builds += len(builds)
+			summary.Jobs = append(summary.Jobs, failureCount{j, len(builds)})
+		}
+		sortFailureCounts(summary.Tests)
+		sortFailureCounts(summary.Jobs)
+		summaries = append(summaries, summary)
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		if summaries[i].Failures == summaries[j].Failures {
+			return summaries[i].ID < summaries[j].ID
+		}
+		return summaries[i].Failures > summaries[j].Failures
+	})
+	return summaries, nil
+}
+
+// sortFailureCounts sorts by most failures, then by name.
+func sortFailureCounts(counts []failureCount) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Failures == counts[j].Failures {
+			return counts[i].Name < counts[j].Name
+		}
+		return counts[i].Failures > counts[j].Failures
+	})
+}
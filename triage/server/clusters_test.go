@@ -0,0 +1,138 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testData = `{
+  "clustered": [
+    {
+      "key": "timeout",
+      "id": "aaa",
+      "text": "timed out",
+      "owner": "node",
+      "tests": [
+        {"name": "test-one", "jobs": [{"name": "dense", "builds": ["10", "12"]}, {"name": "sparse", "builds": ["7"]}]},
+        {"name": "test-two", "jobs": [{"name": "dense", "builds": ["12"]}]}
+      ]
+    },
+    {
+      "key": "panic",
+      "id": "bbb",
+      "text": "panic",
+      "owner": "storage",
+      "tests": [
+        {"name": "test-three", "jobs": [{"name": "sparse", "builds": ["7", "404"]}]}
+      ]
+    }
+  ],
+  "builds": {
+    "jobs": {
+      "dense": [10, 3, 0],
+      "sparse": {"7": 3}
+    },
+    "cols": {"started": [100, 110, 120, 90]}
+  }
+}`
+
+func TestSummarizeClusters(t *testing.T) {
+	data, err := parseFailureData([]byte(testData))
+	if err != nil {
+		t.Fatalf("parseFailureData() returned an error: %s", err)
+	}
+	got, err := summarizeClusters(data)
+	if err != nil {
+		t.Fatalf("summarizeClusters() returned an error: %s", err)
+	}
+
+	want := []clusterSummary{
+		{
+			ID:        "aaa",
+			Key:       "timeout",
+			Text:      "timed out",
+			Owner:     "node",
+			Failures:  4,
+			Builds:    3,
+			Tests:     []failureCount{{"test-one", 3}, {"test-two", 1}},
+			Jobs:      []failureCount{{"dense", 2}, {"sparse", 1}},
+			FirstSeen: 90,
+			LastSeen:  120,
+		},
+		{
+			ID:       "bbb",
+			Key:      "panic",
+			Text:     "panic",
+			Owner:    "storage",
+			Failures: 2,
+			Builds:   2,
+			Tests:    []failureCount{{"test-three", 2}},
+			Jobs:     []failureCount{{"sparse", 2}},
+			// Build 404 is not in the builds, so only build 7 is seen
+			FirstSeen: 90,
+			LastSeen:  90,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("summarizeClusters() = %#v, wanted %#v", got, want)
+	}
+}
+
+func TestBuildStarts(t *testing.T) {
+	data, err := parseFailureData([]byte(testData))
+	if err != nil {
+		t.Fatalf("parseFailureData() returned an error: %s", err)
+	}
+	started, err := data.buildStarts()
+	if err != nil {
+		t.Fatalf("buildStarts() returned an error: %s", err)
+	}
+
+	testCases := []struct {
+		name  string
+		job   string
+		build string
+		start int64
+		found bool
+	}{
+		{"First dense build", "dense", "10", 100, true},
+		{"Last dense build", "dense", "12", 120, true},
+		{"Dense build after the range", "dense", "13", 0, false},
+		{"Dense build before the range", "dense", "9", 0, false},
+		{"Non-numeric dense build", "dense", "abc", 0, false},
+		{"Sparse build", "sparse", "7", 90, true},
+		{"Unknown sparse build", "sparse", "8", 0, false},
+		{"Unknown job", "missing", "1", 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			start, found := started(tc.job, tc.build)
+			if start != tc.start || found != tc.found {
+				t.Errorf("started(%q, %q) = (%d, %t), wanted (%d, %t)", tc.job, tc.build, start, found, tc.start, tc.found)
+			}
+		})
+	}
+}
+
+func TestParseFailureDataInvalid(t *testing.T) {
+	if _, err := parseFailureData([]byte("{")); err == nil {
+		t.Errorf("parseFailureData() did not return an error for invalid JSON")
+	}
+}
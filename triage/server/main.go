@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The server command serves summaries of the failure clusters found by summarize over a JSON API.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const defaultData = "https://storage.googleapis.com/k8s-gubernator/triage/failure_data.json"
+
+// serverFlags represents the command-line arguments to the server and their values.
+type serverFlags struct {
+	data    string
+	port    int
+	refresh time.Duration
+}
+
+// parseFlags parses command-line arguments and returns them as a serverFlags object.
+func parseFlags() serverFlags {
+	var flags serverFlags
+
+	flag.StringVar(&flags.data, "data", defaultData, "path or http(s) URL of the failure_data.json written by summarize")
+	flag.IntVar(&flags.port, "port", 8080, "port to serve the API on")
+	flag.DurationVar(&flags.refresh, "refresh", 10*time.Minute, "how often to reload the failure data")
+	klog.InitFlags(nil)
+	flag.Parse()
+
+	if flags.refresh <= 0 {
+		klog.Fatalf("refresh must be positive")
+	}
+
+	return flags
+}
+
+// readData reads the failure data from a local path or an http(s) URL, such as a public GCS object.
+func readData(location string) ([]byte, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		return ioutil.ReadFile(location)
+	}
+
+	resp, err := http.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Unexpected status fetching %s: %s", location, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// load reads, parses and summarizes the failure data.
+func load(location string) ([]clusterSummary, error) {
+	contents, err := readData(location)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read failure data: %s", err)
+	}
+	data, err := parseFailureData(contents)
+	if err != nil {
+		return nil, err
+	}
+	return summarizeClusters(data)
+}
+
+func main() {
+	flags := parseFlags()
+
+	s := &server{}
+	clusters, err := load(flags.data)
+	if err != nil {
+		klog.Fatalf("Could not load %s: %s", flags.data, err)
+	}
+	s.update(clusters)
+	klog.Infof("Loaded %d clusters", len(clusters))
+
+	go func() {
+		for range time.Tick(flags.refresh) {
+			clusters, err := load(flags.data)
+			if err != nil {
+				klog.Warningf("Could not reload %s, serving the previous clusters: %s", flags.data, err)
+				continue
+			}
+			s.update(clusters)
+			klog.V(2).Infof("Reloaded %d clusters", len(clusters))
+		}
+	}()
+
+	http.Handle(clustersPath, s)
+	http.Handle(clustersPath+"/", s)
+	klog.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", flags.port), nil))
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	clustersPath = "/api/v1/clusters"
+	// defaultLimit is the number of clusters listed when the request does not specify a limit
+	defaultLimit = 100
+	// listedCounts is the number of tests and jobs included per cluster when listing clusters
+	listedCounts = 5
+)
+
+// server serves the most recently loaded cluster summaries.
+type server struct {
+	lock     sync.RWMutex
+	clusters []clusterSummary
+	byID     map[string]clusterSummary
+}
+
+// update replaces the served clusters.
+func (s *server) update(clusters []clusterSummary) {
+	byID := make(map[string]clusterSummary, len(clusters))
+	for _, c := range clusters {
+		byID[c.ID] = c
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.clusters = clusters
+	s.byID = byID
+}
+
+// ServeHTTP serves GET /api/v1/clusters and GET /api/v1/clusters/{id}.
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case path == clustersPath:
+		s.listClusters(w, r)
+	case strings.HasPrefix(path, clustersPath+"/"):
+		s.getCluster(w, strings.TrimPrefix(path, clustersPath+"/"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// listClusters writes the clusters with the most failures, optionally filtered by owner and job.
+// Only the top tests and jobs of each cluster are included.
+func (s *server) listClusters(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	owner := query.Get("owner")
+	job := query.Get("job")
+	limit := defaultLimit
+	if l := query.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 0 {
+			http.Error(w, "limit must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	listed := []clusterSummary{}
+	for _, c := range s.clusters {
+		if len(listed) >= limit {
+			break
+		}
+		if owner != "" && c.Owner != owner {
+			continue
+		}
+		if job != "" && !hasJob(c, job) {
+			continue
+		}
+		if len(c.Tests) > listedCounts {
+			c.Tests = c.Tests[:listedCounts]
+		}
+		if len(c.Jobs) > listedCounts {
+			c.Jobs = c.Jobs[:listedCounts]
+		}
+		listed = append(listed, c)
+	}
+	writeJSON(w, listed)
+}
+
+// getCluster writes the full summary of a single cluster.
+func (s *server) getCluster(w http.ResponseWriter, id string) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	c, ok := s.byID[id]
+	if !ok {
+		http.Error(w, "Unknown cluster "+id, http.StatusNotFound)
+		return
+	}
+	writeJSON(w, c)
+}
+
+func hasJob(c clusterSummary, job string) bool {
+	for _, j := range c.Jobs {
+		if j.Name == job {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	// Deck renders the clusters from a different origin
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		klog.Errorf("Could not write response: %s", err)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func newTestServer() *server {
+	s := &server{}
+	s.update([]clusterSummary{
+		{
+			ID:       "aaa",
+			Owner:    "node",
+			Failures: 10,
+			Tests:    []failureCount{{"t1", 3}, {"t2", 2}, {"t3", 2}, {"t4", 1}, {"t5", 1}, {"t6", 1}},
+			Jobs:     []failureCount{{"job-a", 5}},
+		},
+		{
+			ID:       "bbb",
+			Owner:    "storage",
+			Failures: 5,
+			Jobs:     []failureCount{{"job-b", 5}},
+		},
+		{
+			ID:       "ccc",
+			Owner:    "node",
+			Failures: 1,
+			Jobs:     []failureCount{{"job-b", 1}},
+		},
+	})
+	return s
+}
+
+func TestListClusters(t *testing.T) {
+	testCases := []struct {
+		name   string
+		url    string
+		status int
+		ids    []string
+	}{
+		{"All", "/api/v1/clusters", http.StatusOK, []string{"aaa", "bbb", "ccc"}},
+		{"Trailing slash", "/api/v1/clusters/", http.StatusOK, []string{"aaa", "bbb", "ccc"}},
+		{"By owner", "/api/v1/clusters?owner=node", http.StatusOK, []string{"aaa", "ccc"}},
+		{"By job", "/api/v1/clusters?job=job-b", http.StatusOK, []string{"bbb", "ccc"}},
+		{"By owner and job", "/api/v1/clusters?owner=node&job=job-b", http.StatusOK, []string{"ccc"}},
+		{"Limited", "/api/v1/clusters?limit=2", http.StatusOK, []string{"aaa", "bbb"}},
+		{"No matches", "/api/v1/clusters?owner=network", http.StatusOK, []string{}},
+		{"Invalid limit", "/api/v1/clusters?limit=many", http.StatusBadRequest, nil},
+	}
+
+	s := newTestServer()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			s.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.url, nil))
+			if rr.Code != tc.status {
+				t.Fatalf("GET %s returned status %d, wanted %d", tc.url, rr.Code, tc.status)
+			}
+			if tc.status != http.StatusOK {
+				return
+			}
+
+			var clusters []clusterSummary
+			if err := json.Unmarshal(rr.Body.Bytes(), &clusters); err != nil {
+				t.Fatalf("GET %s returned invalid JSON: %s", tc.url, err)
+			}
+			ids := []string{}
+			for _, c := range clusters {
+				ids = append(ids, c.ID)
+				if len(c.Tests) > listedCounts || len(c.Jobs) > listedCounts {
+					t.Errorf("GET %s listed %d tests and %d jobs for cluster %s, wanted at most %d", tc.url, len(c.Tests), len(c.Jobs), c.ID, listedCounts)
+				}
+			}
+			if !reflect.DeepEqual(ids, tc.ids) {
+				t.Errorf("GET %s listed clusters %v, wanted %v", tc.url, ids, tc.ids)
+			}
+		})
+	}
+}
+
+func TestGetCluster(t *testing.T) {
+	s := newTestServer()
+
+	rr := httptest.NewRecorder()
+	s.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/clusters/aaa", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET returned status %d, wanted %d", rr.Code, http.StatusOK)
+	}
+	var c clusterSummary
+	if err := json.Unmarshal(rr.Body.Bytes(), &c); err != nil {
+		t.Fatalf("GET returned invalid JSON: %s", err)
+	}
+	if !reflect.DeepEqual(c, s.byID["aaa"]) {
+		t.Errorf("GET returned %#v, wanted %#v", c, s.byID["aaa"])
+	}
+
+	rr = httptest.NewRecorder()
+	s.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/clusters/zzz", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("GET of an unknown cluster returned status %d, wanted %d", rr.Code, http.StatusNotFound)
+	}
+
+	rr = httptest.NewRecorder()
+	s.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/v1/clusters/aaa", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST returned status %d, wanted %d", rr.Code, http.StatusMethodNotAllowed)
+	}
+}